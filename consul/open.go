@@ -0,0 +1,28 @@
+package consul
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("consul", open)
+}
+
+// open creates the source of consul://host:8500/prefix?token=secret&tls=true
+func open(u *url.URL) (migi.Source, error) {
+	query := u.Query()
+	scheme := "http"
+	if tls, _ := strconv.ParseBool(query.Get("tls")); tls {
+		scheme = "https"
+	}
+
+	var options []Option
+	if token := query.Get("token"); token != "" {
+		options = append(options, WithToken(token))
+	}
+
+	return NewSource(scheme+"://"+u.Host, u.Path, options...), nil
+}
@@ -0,0 +1,160 @@
+package consul
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi/httpsource"
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+type (
+	// Option customizes the consul source
+	Option func(*source)
+
+	// pair is an entry of the consul kv api response
+	pair struct {
+		Key         string
+		Value       []byte
+		ModifyIndex uint64
+	}
+
+	source struct {
+		*mapsource.Source
+		address string
+		prefix  string
+		token   string
+		client  *http.Client
+		index   uint64
+	}
+)
+
+// WithToken sets the ACL token sent on every request
+func WithToken(token string) Option {
+	return func(s *source) {
+		s.token = token
+	}
+}
+
+// WithClient replaces the default http client, which times out after httpsource.DefaultTimeout.
+// Blocking queries extend the client timeout by their wait time
+func WithClient(client *http.Client) Option {
+	return func(s *source) {
+		s.client = client
+	}
+}
+
+func (s *source) Load() error {
+	_, err := s.fetch(0)
+	return err
+}
+
+// Keys lists the option names of the keys under the prefix, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+// Wait issues a blocking query that returns when any key under the prefix
+// changes or the wait time elapses, reloading the values when they changed
+func (s *source) Wait(wait time.Duration) (bool, error) {
+	return s.fetch(wait)
+}
+
+func (s *source) fetch(wait time.Duration) (bool, error) {
+	query := url.Values{"recurse": []string{"true"}}
+	if wait > 0 && s.index > 0 {
+		query.Set("index", strconv.FormatUint(s.index, 10))
+		query.Set("wait", wait.String())
+	}
+	endpoint := strings.TrimSuffix(s.address, "/") + "/v1/kv/" + s.keyPrefix() + "?" + query.Encode()
+
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	if s.token != "" {
+		request.Header.Set("X-Consul-Token", s.token)
+	}
+
+	client := s.client
+	if wait > 0 && client.Timeout > 0 {
+		// consul holds a blocking query up to wait plus a jitter of wait/16
+		blocking := *client
+		blocking.Timeout += wait + wait/16
+		client = &blocking
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	var pairs []pair
+	switch response.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(response.Body).Decode(&pairs); err != nil {
+			return false, err
+		}
+	case http.StatusNotFound:
+	default:
+		return false, httpsource.NewStatusError(endpoint, response.StatusCode)
+	}
+
+	index, _ := strconv.ParseUint(response.Header.Get("X-Consul-Index"), 10, 64)
+	if wait > 0 && index == s.index {
+		return false, nil
+	}
+	if index < s.index {
+		// the index went backwards, the next blocking query must start over
+		index = 0
+	}
+	s.index = index
+	s.Set(s.values(pairs))
+
+	return true, nil
+}
+
+// keyPrefix returns the prefix as a key path ending with a slash, consul
+// matches keys by plain string prefix so app would also match apple/key
+func (s *source) keyPrefix() string {
+	prefix := strings.Trim(s.prefix, "/")
+	if prefix == "" {
+		return ""
+	}
+
+	return prefix + "/"
+}
+
+func (s *source) values(pairs []pair) map[string]interface{} {
+	prefix := s.keyPrefix()
+	values := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		if strings.HasSuffix(pair.Key, "/") || !strings.HasPrefix(pair.Key, prefix) {
+			continue
+		}
+		name := strings.TrimPrefix(pair.Key, prefix)
+		values[strings.Replace(name, "/", ".", -1)] = string(pair.Value)
+	}
+
+	return values
+}
+
+// NewSource creates a source that reads every key under prefix from the
+// consul agent at address, naming options by the key path joined by dots
+func NewSource(address string, prefix string, options ...Option) *source {
+	s := &source{
+		Source:  mapsource.New(nil),
+		address: address,
+		prefix:  prefix,
+		client:  &http.Client{Timeout: httpsource.DefaultTimeout},
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
@@ -0,0 +1,173 @@
+package consul
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/httpsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAgent is a stand-in for the consul kv http api
+type testAgent struct {
+	index    uint64
+	kv       map[string]string
+	token    string
+	requests []*http.Request
+	// delay holds the blocking queries
+	delay time.Duration
+}
+
+func (a *testAgent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.requests = append(a.requests, r)
+	if r.URL.Query().Get("index") != "" {
+		time.Sleep(a.delay)
+	}
+	if a.token != "" && r.Header.Get("X-Consul-Token") != a.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(a.index, 10))
+
+	var pairs []map[string]interface{}
+	for key, value := range a.kv {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		pair := map[string]interface{}{"Key": key, "ModifyIndex": a.index}
+		if !strings.HasSuffix(key, "/") {
+			pair["Value"] = base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		pairs = append(pairs, pair)
+	}
+	if len(pairs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(pairs)
+}
+
+func TestSource(t *testing.T) {
+	agent := &testAgent{
+		index: 10,
+		token: "my_token",
+		kv: map[string]string{
+			"app/":             "",
+			"app/string_key":   "string_value",
+			"app/int_key":      "333",
+			"app/db/host":      "db.local",
+			"app/db/timeout":   "5s",
+			"other/string_key": "other_value",
+		},
+	}
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	source := NewSource(server.URL, "app/", WithToken("my_token"))
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	intValue, err := source.Int("int_key")
+	assert.NoError(t, err)
+	assert.Equal(t, 333, intValue)
+	stringValue, err = source.String("db.host")
+	assert.NoError(t, err)
+	assert.Equal(t, "db.local", stringValue)
+	durationValue, err := source.Duration("db.timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second*5, durationValue)
+	_, err = source.String("other.string_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+	assert.Equal(t, "true", agent.requests[0].URL.Query().Get("recurse"))
+
+	changed, err := source.Wait(time.Second)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "10", agent.requests[1].URL.Query().Get("index"))
+	assert.Equal(t, "1s", agent.requests[1].URL.Query().Get("wait"))
+
+	agent.index = 11
+	agent.kv["app/string_key"] = "new_value"
+	changed, err = source.Wait(time.Second)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	stringValue, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "new_value", stringValue)
+}
+
+func TestSourcePrefixWithoutSlash(t *testing.T) {
+	agent := &testAgent{
+		index: 1,
+		kv: map[string]string{
+			"app/string_key":   "string_value",
+			"apple/string_key": "apple_value",
+			"key":              "root_value",
+		},
+	}
+	server := httptest.NewServer(agent)
+	defer server.Close()
+
+	source := NewSource(server.URL, "app")
+	require.NoError(t, source.Load())
+	assert.Equal(t, "/v1/kv/app/", agent.requests[0].URL.Path)
+	assert.Equal(t, []string{"string_key"}, source.Keys())
+
+	source = NewSource(server.URL, "")
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"app.string_key", "apple.string_key", "key"}, source.Keys())
+}
+
+func TestSourceEmptyPrefix(t *testing.T) {
+	server := httptest.NewServer(&testAgent{index: 1})
+	defer server.Close()
+
+	source := NewSource(server.URL, "missing/")
+	require.NoError(t, source.Load())
+	_, err := source.String("string_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+}
+
+func TestSourceError(t *testing.T) {
+	server := httptest.NewServer(&testAgent{index: 1, token: "my_token"})
+	defer server.Close()
+
+	source := NewSource(server.URL, "app/")
+	assert.IsType(t, httpsource.StatusError{}, source.Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("consul://consul.local:8500/app/?token=my_token&tls=true")
+	require.NoError(t, err)
+	openedSource := opened.(*source)
+	assert.Equal(t, "https://consul.local:8500", openedSource.address)
+	assert.Equal(t, "/app/", openedSource.prefix)
+	assert.Equal(t, "my_token", openedSource.token)
+}
+
+func TestSourceWaitTimeout(t *testing.T) {
+	server := httptest.NewServer(&testAgent{
+		index: 1,
+		kv:    map[string]string{"app/string_key": "string_value"},
+		delay: time.Millisecond * 150,
+	})
+	defer server.Close()
+
+	source := NewSource(server.URL, "app/", WithClient(&http.Client{Timeout: time.Millisecond * 50}))
+	require.NoError(t, source.Load())
+	changed, err := source.Wait(time.Second)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
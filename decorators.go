@@ -0,0 +1,395 @@
+package migi
+
+import (
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Decorator wraps a source changing how it serves its options
+	Decorator func(source Source) Source
+
+	// mappedSource serves the options of a source under other names,
+	// keys returns the source keys holding an option, none hides it,
+	// and names lists the option names served for the source keys
+	mappedSource struct {
+		Source
+		keys      func(name string) []string
+		names     func(keys []string) []string
+		decorator Decorator
+	}
+
+	cacheKey struct {
+		kind string
+		name string
+	}
+
+	cacheEntry struct {
+		value   interface{}
+		err     error
+		expires time.Time
+	}
+
+	// cacheSource keeps the values read from a source for a while
+	cacheSource struct {
+		Source
+		ttl     time.Duration
+		now     func() time.Time
+		mutex   sync.Mutex
+		entries map[cacheKey]cacheEntry
+		// reload is when Load reaches the wrapped source again
+		reload time.Time
+	}
+)
+
+// Decorate wraps source with decorators, the first one wraps it directly
+func Decorate(source Source, decorators ...Decorator) Source {
+	for _, decorator := range decorators {
+		source = decorator(source)
+	}
+
+	return source
+}
+
+// WithAliases makes an option name, a key of aliases, also read from the source key it aliases
+// when the source does not hold the option itself
+func WithAliases(aliases map[string]string) Decorator {
+	return mapped(
+		func(name string) []string {
+			if alias, ok := aliases[name]; ok {
+				return []string{name, alias}
+			}
+			return []string{name}
+		},
+		func(keys []string) []string {
+			held := make(map[string]bool, len(keys))
+			aliased := make(map[string]bool, len(aliases))
+			for _, key := range keys {
+				held[key] = true
+			}
+			var names []string
+			for name, alias := range aliases {
+				if held[alias] {
+					aliased[alias] = true
+					if !held[name] {
+						names = append(names, name)
+					}
+				}
+			}
+			for _, key := range keys {
+				if !aliased[key] {
+					names = append(names, key)
+				}
+			}
+			return names
+		},
+	)
+}
+
+// WithRename reads every option from the source key returned by rename, e.g. strings.ToUpper.
+// Renamed sources list no keys since rename can not be reversed
+func WithRename(rename func(name string) string) Decorator {
+	return mapped(
+		func(name string) []string {
+			return []string{rename(name)}
+		},
+		func(keys []string) []string {
+			return nil
+		},
+	)
+}
+
+// WithAllowList hides the options whose names match none of the patterns, see path.Match
+func WithAllowList(patterns ...string) Decorator {
+	return filtered(func(name string) bool {
+		return matchAny(patterns, name)
+	})
+}
+
+// WithDenyList hides the options whose names match any of the patterns, see path.Match
+func WithDenyList(patterns ...string) Decorator {
+	return filtered(func(name string) bool {
+		return !matchAny(patterns, name)
+	})
+}
+
+// WithCache keeps the values, and the options not found, read from the source
+// for ttl. Load reaches the source, fetching it again and dropping every cached
+// value, only once ttl elapsed since its last load
+func WithCache(ttl time.Duration) Decorator {
+	return func(source Source) Source {
+		return &cacheSource{
+			Source:  source,
+			ttl:     ttl,
+			now:     time.Now,
+			entries: make(map[cacheKey]cacheEntry),
+		}
+	}
+}
+
+func mapped(keys func(name string) []string, names func(keys []string) []string) Decorator {
+	var decorator Decorator
+	decorator = func(source Source) Source {
+		return &mappedSource{Source: source, keys: keys, names: names, decorator: decorator}
+	}
+
+	return decorator
+}
+
+// filtered serves only the options whose names are allowed
+func filtered(allowed func(name string) bool) Decorator {
+	return mapped(
+		func(name string) []string {
+			if !allowed(name) {
+				return nil
+			}
+			return []string{name}
+		},
+		func(keys []string) []string {
+			var names []string
+			for _, key := range keys {
+				if allowed(key) {
+					names = append(names, key)
+				}
+			}
+			return names
+		},
+	)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decoratedProfile returns the profile overlay of source wrapped by decorator
+func decoratedProfile(source Source, decorator Decorator, name string) (Source, error) {
+	profileSource, is := source.(ProfileSource)
+	if !is {
+		return nil, nil
+	}
+	overlay, err := profileSource.Profile(name)
+	if err != nil || overlay == nil {
+		return overlay, err
+	}
+
+	return decorator(overlay), nil
+}
+
+func (m *mappedSource) Provenance() string {
+	return provenance(m.Source)
+}
+
+func (m *mappedSource) Keys() []string {
+	keys := m.names(sortedKeys(m.Source))
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (m *mappedSource) Profile(name string) (Source, error) {
+	return decoratedProfile(m.Source, m.decorator, name)
+}
+
+// read calls get with the source keys of the option until one of them is found
+func (m *mappedSource) read(name string, get func(key string) error) error {
+	for _, key := range m.keys(name) {
+		err := get(key)
+		if _, is := err.(OptionNotFound); is {
+			continue
+		}
+		return err
+	}
+
+	return NewOptionNotFound(name)
+}
+
+func (m *mappedSource) Value(name string) (interface{}, error) {
+	var value interface{}
+	err := m.read(name, func(key string) (err error) {
+		value, err = rawValue(m.Source, key)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mappedSource) String(name string) (string, error) {
+	var value string
+	err := m.read(name, func(key string) (err error) {
+		value, err = m.Source.String(key)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mappedSource) Int(name string) (int, error) {
+	var value int
+	err := m.read(name, func(key string) (err error) {
+		value, err = m.Source.Int(key)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mappedSource) Float(name string) (float32, error) {
+	var value float32
+	err := m.read(name, func(key string) (err error) {
+		value, err = m.Source.Float(key)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mappedSource) Bool(name string) (bool, error) {
+	var value bool
+	err := m.read(name, func(key string) (err error) {
+		value, err = m.Source.Bool(key)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mappedSource) Time(name string) (time.Time, error) {
+	var value time.Time
+	err := m.read(name, func(key string) (err error) {
+		value, err = m.Source.Time(key)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mappedSource) Duration(name string) (time.Duration, error) {
+	var value time.Duration
+	err := m.read(name, func(key string) (err error) {
+		value, err = m.Source.Duration(key)
+		return err
+	})
+
+	return value, err
+}
+
+func (c *cacheSource) Load() error {
+	c.mutex.Lock()
+	fresh := c.now().Before(c.reload)
+	c.mutex.Unlock()
+	if fresh {
+		return nil
+	}
+
+	if err := c.Source.Load(); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[cacheKey]cacheEntry)
+	c.reload = c.now().Add(c.ttl)
+
+	return nil
+}
+
+func (c *cacheSource) Provenance() string {
+	return provenance(c.Source)
+}
+
+func (c *cacheSource) Keys() []string {
+	return sortedKeys(c.Source)
+}
+
+func (c *cacheSource) Profile(name string) (Source, error) {
+	return decoratedProfile(c.Source, WithCache(c.ttl), name)
+}
+
+// get returns the cached kind of value of the option, reading and caching it when expired.
+// Errors other than OptionNotFound are never cached
+func (c *cacheSource) get(kind string, name string, read func() (interface{}, error)) (interface{}, error) {
+	key := cacheKey{kind: kind, name: name}
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	c.mutex.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		return entry.value, entry.err
+	}
+
+	value, err := read()
+	if _, is := err.(OptionNotFound); err == nil || is {
+		c.mutex.Lock()
+		c.entries[key] = cacheEntry{value: value, err: err, expires: c.now().Add(c.ttl)}
+		c.mutex.Unlock()
+	}
+
+	return value, err
+}
+
+func (c *cacheSource) Value(name string) (interface{}, error) {
+	return c.get("value", name, func() (interface{}, error) {
+		return rawValue(c.Source, name)
+	})
+}
+
+func (c *cacheSource) String(name string) (string, error) {
+	value, err := c.get("string", name, func() (interface{}, error) {
+		return c.Source.String(name)
+	})
+	stringValue, _ := value.(string)
+
+	return stringValue, err
+}
+
+func (c *cacheSource) Int(name string) (int, error) {
+	value, err := c.get("int", name, func() (interface{}, error) {
+		return c.Source.Int(name)
+	})
+	intValue, _ := value.(int)
+
+	return intValue, err
+}
+
+func (c *cacheSource) Float(name string) (float32, error) {
+	value, err := c.get("float", name, func() (interface{}, error) {
+		return c.Source.Float(name)
+	})
+	floatValue, _ := value.(float32)
+
+	return floatValue, err
+}
+
+func (c *cacheSource) Bool(name string) (bool, error) {
+	value, err := c.get("bool", name, func() (interface{}, error) {
+		return c.Source.Bool(name)
+	})
+	boolValue, _ := value.(bool)
+
+	return boolValue, err
+}
+
+func (c *cacheSource) Time(name string) (time.Time, error) {
+	value, err := c.get("time", name, func() (interface{}, error) {
+		return c.Source.Time(name)
+	})
+	timeValue, _ := value.(time.Time)
+
+	return timeValue, err
+}
+
+func (c *cacheSource) Duration(name string) (time.Duration, error) {
+	value, err := c.get("duration", name, func() (interface{}, error) {
+		return c.Source.Duration(name)
+	})
+	durationValue, _ := value.(time.Duration)
+
+	return durationValue, err
+}
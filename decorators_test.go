@@ -0,0 +1,193 @@
+package migi
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// countingSource counts the string reads reaching the wrapped source
+	countingSource struct {
+		mockSource
+		reads int
+	}
+
+	testDecorator struct {
+		name       string
+		decorators []Decorator
+		expected   map[string]interface{}
+		notFound   []string
+	}
+)
+
+func (c *countingSource) String(name string) (string, error) {
+	c.reads++
+	return c.mockSource.String(name)
+}
+
+func TestDecorate(t *testing.T) {
+	source := &mockSource{options: map[string]interface{}{
+		"string_key":   "string_value",
+		"int_key":      333,
+		"DB_HOST":      "db.local",
+		"db.password":  "secret",
+		"db.user":      "user",
+		"duration_key": time.Second,
+	}}
+
+	scenarios := []testDecorator{
+		{
+			name:       "with aliases",
+			decorators: []Decorator{WithAliases(map[string]string{"db.host": "DB_HOST", "string_key": "DB_HOST", "old_key": "missing_key"})},
+			expected:   map[string]interface{}{"db.host": "db.local", "string_key": "string_value", "int_key": 333},
+			notFound:   []string{"old_key"},
+		},
+		{
+			name: "with rename",
+			decorators: []Decorator{WithRename(func(name string) string {
+				return strings.ToUpper(strings.Replace(name, ".", "_", -1))
+			})},
+			expected: map[string]interface{}{"db.host": "db.local"},
+			notFound: []string{"string_key", "db.password"},
+		},
+		{
+			name:       "with allow list",
+			decorators: []Decorator{WithAllowList("db.*", "int_key")},
+			expected:   map[string]interface{}{"db.user": "user", "int_key": 333},
+			notFound:   []string{"string_key", "DB_HOST"},
+		},
+		{
+			name:       "with deny list",
+			decorators: []Decorator{WithDenyList("db.pass*")},
+			expected:   map[string]interface{}{"db.user": "user", "duration_key": time.Second},
+			notFound:   []string{"db.password"},
+		},
+		{
+			name: "with composed decorators",
+			decorators: []Decorator{
+				WithDenyList("db.password"),
+				WithAliases(map[string]string{"db.secret": "db.password", "db.login": "db.user"}),
+				WithCache(time.Minute),
+			},
+			expected: map[string]interface{}{"db.login": "user", "int_key": 333},
+			notFound: []string{"db.secret", "db.password"},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				decorated := Decorate(source, scenario.decorators...)
+				require.NoError(t, decorated.Load())
+
+				for key, value := range scenario.expected {
+					switch value.(type) {
+					case string:
+						v, err := decorated.String(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v)
+					case int:
+						v, err := decorated.Int(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v)
+					case time.Duration:
+						v, err := decorated.Duration(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v)
+					}
+					v, err := decorated.(Valuer).Value(key)
+					assert.NoError(t, err)
+					assert.Equal(t, value, v)
+				}
+
+				for _, key := range scenario.notFound {
+					_, err := decorated.String(key)
+					assert.Equal(t, NewOptionNotFound(key), err)
+					_, err = decorated.Int(key)
+					assert.Equal(t, NewOptionNotFound(key), err)
+				}
+			},
+		)
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	source := &countingSource{mockSource: mockSource{options: map[string]interface{}{
+		"string_key": "string_value",
+		"error_key":  errors.New("mock_error"),
+	}}}
+	decorated := WithCache(time.Minute)(source)
+	now := time.Date(2019, 5, 23, 0, 0, 0, 0, time.UTC)
+	decorated.(*cacheSource).now = func() time.Time { return now }
+
+	for _, key := range []string{"string_key", "string_key", "missing_key", "missing_key"} {
+		decorated.String(key)
+	}
+	assert.Equal(t, 2, source.reads)
+
+	_, err := decorated.String("error_key")
+	assert.EqualError(t, err, "mock_error")
+	_, err = decorated.String("error_key")
+	assert.EqualError(t, err, "mock_error")
+	assert.Equal(t, 4, source.reads)
+
+	now = now.Add(time.Minute)
+	value, err := decorated.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", value)
+	assert.Equal(t, 5, source.reads)
+
+	require.NoError(t, decorated.Load())
+	decorated.String("string_key")
+	assert.Equal(t, 6, source.reads)
+}
+
+func TestWithCacheLoad(t *testing.T) {
+	source := &loadCountingSource{mockSource: mockSource{options: map[string]interface{}{"string_key": "string_value"}}}
+	decorated := WithCache(time.Minute)(source)
+	now := time.Date(2019, 5, 23, 0, 0, 0, 0, time.UTC)
+	decorated.(*cacheSource).now = func() time.Time { return now }
+
+	options := NewOptions(decorated)
+	value := options.String("string_key", "", "")
+	require.NoError(t, options.Load())
+	require.NoError(t, options.Load())
+	assert.Equal(t, "string_value", *value)
+	assert.Equal(t, 1, source.loads)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, options.Load())
+	assert.Equal(t, 2, source.loads)
+}
+
+func TestDecorateOptions(t *testing.T) {
+	options := NewOptions(
+		Decorate(
+			&profileSource{
+				Source: &provenanceMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"APP_PROFILE": "prod", "DB_HOST": "db.local"}},
+					provenance: "base",
+				},
+				profiles: map[string]Source{
+					"prod": &mockSource{options: map[string]interface{}{"DB_HOST": "db.prod.local"}},
+				},
+			},
+			WithRename(func(name string) string {
+				return strings.ToUpper(strings.Replace(name, ".", "_", -1))
+			}),
+		),
+	)
+	options.(ProfileOptions).Profile("app.profile", "", "")
+	host := options.String("db.host", "", "")
+	require.NoError(t, options.Load())
+
+	assert.Equal(t, "db.prod.local", *host)
+	assert.Equal(t, "*migi.mockSource (profile prod)", options.(ProvenanceOptions).Provenance("db.host"))
+}
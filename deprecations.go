@@ -0,0 +1,80 @@
+package migi
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/rjansen/abend"
+)
+
+// deprecate assigns the deprecated names to the registered options,
+// it reports the deprecated options that are not registered
+func (o *options) deprecate() error {
+	registered := make(map[string]bool, len(o.register))
+	for _, option := range o.register {
+		option.deprecated = o.deprecations[option.name]
+		registered[option.name] = true
+	}
+
+	var names []string
+	for name := range o.deprecations {
+		if !registered[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	sort.Strings(names)
+	errs := make([]error, len(names))
+	for index, name := range names {
+		errs[index] = NewOptionNotRegistered(name)
+	}
+
+	return abend.NewList(errs...)
+}
+
+// scanDeprecated reads the deprecated names of the option, they supply its
+// value only when its name is not set and must agree with it otherwise
+func (o *option) scanDeprecated(layers []layer) []error {
+	var errs []error
+	o.deprecatedSetted = nil
+	for _, name := range o.deprecated {
+		deprecated := &option{
+			name:    name,
+			pointer: reflect.New(reflect.TypeOf(o.pointer).Elem()).Interface(),
+		}
+		if scanErrs := deprecated.scan(layers...); len(scanErrs) > 0 {
+			errs = append(errs, scanErrs...)
+			continue
+		}
+		if !deprecated.setted {
+			continue
+		}
+		o.deprecatedSetted = append(o.deprecatedSetted, name)
+
+		if o.setted {
+			if formatPointer(deprecated.pointer) != formatPointer(o.pointer) {
+				errs = append(errs, NewOptionConflict(o.name, name))
+			}
+			continue
+		}
+		reflect.ValueOf(o.pointer).Elem().Set(reflect.ValueOf(deprecated.pointer).Elem())
+		o.setted = true
+		o.provenance = deprecated.provenance
+	}
+
+	return errs
+}
+
+// warnDeprecated warns about the deprecated names supplying the loaded options
+func (o *options) warnDeprecated() {
+	if o.warning == nil {
+		return
+	}
+	for _, option := range o.register {
+		for _, deprecated := range option.deprecatedSetted {
+			o.warning(NewOptionDeprecated(option.name, deprecated))
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package migi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rjansen/abend"
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	testDeprecated struct {
+		name     string
+		sources  []Source
+		expected testDeprecatedExpected
+	}
+
+	testDeprecatedExpected struct {
+		timeout    time.Duration
+		provenance string
+		warnings   []error
+		loadError  error
+	}
+)
+
+func TestOptionsDeprecate(t *testing.T) {
+	scenarios := []testDeprecated{
+		{
+			name: "when only the new name is set",
+			sources: []Source{
+				&provenanceMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"http.timeout": time.Second}},
+					provenance: "base",
+				},
+			},
+			expected: testDeprecatedExpected{timeout: time.Second, provenance: "base"},
+		},
+		{
+			name: "when only a deprecated name is set",
+			sources: []Source{
+				&provenanceMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"http_timeout": time.Second * 2}},
+					provenance: "legacy",
+				},
+			},
+			expected: testDeprecatedExpected{
+				timeout:    time.Second * 2,
+				provenance: "legacy",
+				warnings:   []error{NewOptionDeprecated("http.timeout", "http_timeout")},
+			},
+		},
+		{
+			name: "when both names are set with the same value",
+			sources: []Source{
+				&provenanceMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"HTTP_TIMEOUT": time.Second * 3}},
+					provenance: "legacy",
+				},
+				&provenanceMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"http.timeout": time.Second * 3}},
+					provenance: "base",
+				},
+			},
+			expected: testDeprecatedExpected{
+				timeout:    time.Second * 3,
+				provenance: "base",
+				warnings:   []error{NewOptionDeprecated("http.timeout", "HTTP_TIMEOUT")},
+			},
+		},
+		{
+			name: "when both names are set with different values",
+			sources: []Source{
+				&mockSource{options: map[string]interface{}{"http_timeout": time.Second * 4, "http.timeout": time.Second}},
+			},
+			expected: testDeprecatedExpected{
+				timeout:   time.Second,
+				loadError: abend.NewList(NewOptionConflict("http.timeout", "http_timeout")),
+			},
+		},
+		{
+			name: "when no name is set",
+			sources: []Source{
+				&mockSource{options: map[string]interface{}{}},
+			},
+			expected: testDeprecatedExpected{timeout: time.Minute, provenance: defaultProvenance},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				var warnings []error
+				options := NewOptions(scenario.sources...)
+				options.(DeprecatedOptions).OnWarning(func(warning error) {
+					warnings = append(warnings, warning)
+				})
+				options.(DeprecatedOptions).Deprecate("http.timeout", "http_timeout", "HTTP_TIMEOUT")
+				timeout := options.Duration("http.timeout", time.Minute, "http client timeout")
+
+				err := options.Load()
+				if scenario.expected.loadError != nil {
+					assert.Equal(t, scenario.expected.loadError, err)
+					return
+				}
+				require.NoError(t, err)
+				assert.Equal(t, scenario.expected.timeout, *timeout)
+				assert.Equal(t, scenario.expected.provenance, options.(ProvenanceOptions).Provenance("http.timeout"))
+				assert.Equal(t, scenario.expected.warnings, warnings)
+			},
+		)
+	}
+}
+
+func TestOptionsDeprecateStages(t *testing.T) {
+	var warnings []error
+	options := NewOptions(&mockSource{options: map[string]interface{}{"config_file": "base.json"}})
+	options.(DeprecatedOptions).OnWarning(func(warning error) {
+		warnings = append(warnings, warning)
+	})
+	configFile := options.String("config.file", "", "")
+	options.(DeprecatedOptions).Deprecate("config.file", "config_file")
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		return []Source{&mockSource{options: map[string]interface{}{"string_key": *configFile}}}, nil
+	})
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		return []Source{&mockSource{}}, nil
+	})
+
+	require.NoError(t, options.Load())
+	assert.Equal(t, "base.json", *configFile)
+	assert.Equal(t, []error{NewOptionDeprecated("config.file", "config_file")}, warnings)
+}
+
+func TestOptionsDeprecateNotRegistered(t *testing.T) {
+	options := NewOptions(&mockSource{options: map[string]interface{}{"http_timeout": time.Second}})
+	options.Duration("http.timeout", time.Minute, "http client timeout")
+	options.(DeprecatedOptions).Deprecate("http.timeout", "http_timeout")
+	options.(DeprecatedOptions).Deprecate("not_registered", "ignored")
+	options.(DeprecatedOptions).Deprecate("also_not_registered", "ignored")
+
+	assert.Equal(t,
+		abend.NewList(NewOptionNotRegistered("also_not_registered"), NewOptionNotRegistered("not_registered")),
+		options.Load(),
+	)
+}
+
+func TestOptionsDeprecateWithoutHandler(t *testing.T) {
+	options := NewOptions(&mockSource{options: map[string]interface{}{"http_timeout": time.Second}})
+	timeout := options.Duration("http.timeout", time.Minute, "http client timeout")
+	options.(DeprecatedOptions).Deprecate("http.timeout", "http_timeout")
+
+	require.NoError(t, options.Load())
+	assert.Equal(t, time.Second, *timeout)
+}
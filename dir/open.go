@@ -0,0 +1,22 @@
+package dir
+
+import (
+	"net/url"
+	"path/filepath"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("dir", open)
+}
+
+// open creates the source of dir:///etc/config or, relative to the working directory, dir:config
+func open(u *url.URL) (migi.Source, error) {
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+
+	return NewSource(filepath.FromSlash(path)), nil
+}
@@ -0,0 +1,84 @@
+package dir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rjansen/migi/internal/mapsource"
+	"github.com/rjansen/migi/internal/parse"
+)
+
+// dataDir is the symlink Kubernetes swaps atomically when a mounted
+// ConfigMap or Secret is updated
+const dataDir = "..data"
+
+type source struct {
+	*mapsource.Source
+	path string
+}
+
+func (s *source) Load() error {
+	root := s.path
+	if target, err := filepath.EvalSymlinks(filepath.Join(s.path, dataDir)); err == nil {
+		root = target
+	}
+
+	values := make(map[string]interface{})
+	if err := s.walk(root, "", values); err != nil {
+		return err
+	}
+	s.Set(values)
+
+	return nil
+}
+
+// Keys lists the names of the files under the path, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+func (s *source) walk(path string, prefix string, values map[string]interface{}) error {
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		entryPath := filepath.Join(path, entry.Name())
+		info, err := os.Stat(entryPath)
+		if err != nil {
+			return err
+		}
+
+		name := prefix + entry.Name()
+		if info.IsDir() {
+			if err := s.walk(entryPath, name+".", values); err != nil {
+				return err
+			}
+			continue
+		}
+
+		content, err := ioutil.ReadFile(entryPath)
+		if err != nil {
+			return err
+		}
+		values[name] = parse.TrimNewline(string(content))
+	}
+
+	return nil
+}
+
+// NewSource creates a source where each file under path is an option named
+// by its relative path, with directories joined by dots, and valued by its content
+func NewSource(path string) *source {
+	return &source{
+		Source: mapsource.New(nil),
+		path:   path,
+	}
+}
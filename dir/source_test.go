@@ -0,0 +1,155 @@
+package dir
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	testSource struct {
+		name      string
+		setupTest func(*testing.T, string)
+		match     testSourceMatch
+	}
+
+	testSourceMatch struct {
+		options  map[string]interface{}
+		notFound []string
+	}
+)
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestSource(t *testing.T) {
+	scenarios := []testSource{
+		{
+			name: "load options from plain directory",
+			setupTest: func(t *testing.T, path string) {
+				writeFile(t, filepath.Join(path, "string_key"), "string_value\n")
+				writeFile(t, filepath.Join(path, "int_key"), "333")
+				writeFile(t, filepath.Join(path, "float_key"), "333.33")
+				writeFile(t, filepath.Join(path, "bool_key"), "true")
+				writeFile(t, filepath.Join(path, "time_key"), "2019-05-23T00:00:00Z")
+				writeFile(t, filepath.Join(path, "duration_key"), "5m")
+				writeFile(t, filepath.Join(path, "db", "host"), "db.local")
+				writeFile(t, filepath.Join(path, ".hidden"), "hidden_value")
+			},
+			match: testSourceMatch{
+				options: map[string]interface{}{
+					"string_key":   "string_value",
+					"int_key":      333,
+					"float_key":    float32(333.33),
+					"bool_key":     true,
+					"time_key":     testutils.NewTime(t, time.RFC3339, "2019-05-23T00:00:00Z"),
+					"duration_key": time.Minute * 5,
+					"db.host":      "db.local",
+				},
+				notFound: []string{".hidden", "hidden"},
+			},
+		},
+		{
+			name: "load options from kubernetes mount",
+			setupTest: func(t *testing.T, path string) {
+				writeFile(t, filepath.Join(path, "..2019_05_23_00_00_00.000", "string_key"), "string_value")
+				writeFile(t, filepath.Join(path, "..2019_05_23_00_00_00.000", "db", "host"), "db.local")
+				writeFile(t, filepath.Join(path, "..2019_05_22_00_00_00.000", "string_key"), "old_value")
+				require.NoError(t, os.Symlink("..2019_05_23_00_00_00.000", filepath.Join(path, "..data")))
+				require.NoError(t, os.Symlink(filepath.Join("..data", "string_key"), filepath.Join(path, "string_key")))
+				require.NoError(t, os.Symlink(filepath.Join("..data", "db"), filepath.Join(path, "db")))
+			},
+			match: testSourceMatch{
+				options: map[string]interface{}{
+					"string_key": "string_value",
+					"db.host":    "db.local",
+				},
+				notFound: []string{"..data", "..data.string_key"},
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				path, err := ioutil.TempDir("", "migi-dir")
+				require.NoError(t, err)
+				defer os.RemoveAll(path)
+				scenario.setupTest(t, path)
+
+				source := NewSource(path)
+				require.NotNil(t, source)
+				require.Implements(t, (*migi.Source)(nil), source)
+				require.NoError(t, source.Load())
+
+				for key, value := range scenario.match.options {
+					switch value.(type) {
+					case string:
+						v, err := source.String(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					case int:
+						v, err := source.Int(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					case float32:
+						v, err := source.Float(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					case bool:
+						v, err := source.Bool(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					case time.Time:
+						v, err := source.Time(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					case time.Duration:
+						v, err := source.Duration(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					}
+				}
+
+				for _, key := range scenario.match.notFound {
+					_, err := source.String(key)
+					assert.IsType(t, migi.OptionNotFound{}, err)
+				}
+			},
+		)
+	}
+}
+
+func TestSourceMissingDirectory(t *testing.T) {
+	source := NewSource(filepath.Join(os.TempDir(), "migi-dir-missing"))
+	assert.Error(t, source.Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("dir:///etc/config")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/etc/config"), opened.(*source).path)
+}
+
+func TestSourceKeys(t *testing.T) {
+	path, err := ioutil.TempDir("", "migi-dir-keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "db", "host"), "db.local")
+	writeFile(t, filepath.Join(path, "string_key"), "string_value")
+	writeFile(t, filepath.Join(path, ".hidden"), "hidden_value")
+
+	source := NewSource(path)
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"db.host", "string_key"}, source.Keys())
+}
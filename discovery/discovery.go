@@ -0,0 +1,192 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rjansen/migi"
+	jsonsource "github.com/rjansen/migi/json"
+)
+
+const defaultName = "config"
+
+type (
+	// Parser creates the source of the config file at path
+	Parser func(path string) migi.Source
+
+	// Option customizes the config file discovery
+	Option func(*finder)
+
+	finder struct {
+		paths   []string
+		names   []string
+		parsers map[string]Parser
+		first   bool
+	}
+
+	// fileSource describes the discovered file its options come from
+	fileSource struct {
+		migi.Source
+		path string
+	}
+)
+
+func (f fileSource) Provenance() string {
+	return "file:" + f.path
+}
+
+func (f fileSource) Keys() []string {
+	if lister, is := f.Source.(migi.KeyLister); is {
+		return lister.Keys()
+	}
+
+	return nil
+}
+
+// Value returns the raw value of the parsed file, or its string value when
+// the parser source is not a migi.Valuer
+func (f fileSource) Value(name string) (interface{}, error) {
+	if valuer, is := f.Source.(migi.Valuer); is {
+		return valuer.Value(name)
+	}
+
+	return f.Source.String(name)
+}
+
+func (f fileSource) Profile(name string) (migi.Source, error) {
+	if profileSource, is := f.Source.(migi.ProfileSource); is {
+		return profileSource.Profile(name)
+	}
+
+	return nil, nil
+}
+
+// WithSearchPaths replaces the directories returned by SearchPaths, in priority order
+func WithSearchPaths(paths ...string) Option {
+	return func(f *finder) {
+		f.paths = paths
+	}
+}
+
+// WithNames replaces the config file name, without extension, looked up in
+// every directory, in priority order
+func WithNames(names ...string) Option {
+	return func(f *finder) {
+		f.names = names
+	}
+}
+
+// WithParser makes the files with the ext extension, e.g. ".yaml", discoverable and parsed by parser
+func WithParser(ext string, parser Parser) Option {
+	return func(f *finder) {
+		if parser == nil {
+			delete(f.parsers, ext)
+			return
+		}
+		f.parsers[ext] = parser
+	}
+}
+
+// WithFirstFound returns only the highest priority file found instead of all of them
+func WithFirstFound() Option {
+	return func(f *finder) {
+		f.first = true
+	}
+}
+
+// SearchPaths returns the directories searched for the config files of app, in priority order:
+// $XDG_CONFIG_HOME/app, ~/.config/app, /etc/app and the working directory
+func SearchPaths(app string) []string {
+	var paths []string
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		paths = append(paths, filepath.Join(configHome, app))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", app))
+	}
+	paths = append(paths, filepath.Join(string(filepath.Separator), "etc", app))
+	if workingDir, err := os.Getwd(); err == nil {
+		paths = append(paths, workingDir)
+	}
+
+	return unique(paths)
+}
+
+// Find returns the sources of the config files of app found in the search paths.
+// They are ordered from the lowest to the highest priority, as NewOptions
+// expects, so the files found first take precedence over the others
+func Find(app string, options ...Option) ([]migi.Source, error) {
+	f := &finder{
+		paths:   SearchPaths(app),
+		names:   []string{defaultName},
+		parsers: map[string]Parser{".json": jsonFileSource},
+	}
+	for _, option := range options {
+		option(f)
+	}
+
+	files, err := f.files()
+	if err != nil {
+		return nil, err
+	}
+
+	sources := make([]migi.Source, len(files))
+	for index, file := range files {
+		source := f.parsers[filepath.Ext(file)](file)
+		sources[len(files)-1-index] = fileSource{Source: source, path: file}
+	}
+
+	return sources, nil
+}
+
+// files returns the existing config files, in priority order
+func (f *finder) files() ([]string, error) {
+	exts := make([]string, 0, len(f.parsers))
+	for ext := range f.parsers {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	var files []string
+	for _, path := range f.paths {
+		for _, name := range f.names {
+			for _, ext := range exts {
+				file := filepath.Join(path, name+ext)
+				info, err := os.Stat(file)
+				if err != nil {
+					if os.IsNotExist(err) {
+						continue
+					}
+					return nil, err
+				}
+				if info.IsDir() {
+					continue
+				}
+				files = append(files, file)
+				if f.first {
+					return files, nil
+				}
+			}
+		}
+	}
+
+	return files, nil
+}
+
+func jsonFileSource(path string) migi.Source {
+	return jsonsource.NewFileSource(path)
+}
+
+func unique(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	uniques := paths[:0]
+	for _, path := range paths {
+		if path = filepath.Clean(path); !seen[path] {
+			seen[path] = true
+			uniques = append(uniques, path)
+		}
+	}
+
+	return uniques
+}
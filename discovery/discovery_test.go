@@ -0,0 +1,175 @@
+package discovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/testutils"
+	jsonsource "github.com/rjansen/migi/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFind struct {
+	name        string
+	files       map[string]string
+	options     []Option
+	provenances []string
+	value       string
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestFind(t *testing.T) {
+	textParser := WithParser(".txt", func(path string) migi.Source {
+		content, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		return jsonsource.NewSource(strings.NewReader(`{"string_key": "` + strings.TrimSpace(string(content)) + `"}`))
+	})
+
+	scenarios := []testFind{
+		{
+			name: "merges every file found",
+			files: map[string]string{
+				"etc/config.json":  `{"string_key": "etc_value"}`,
+				"home/config.json": `{"string_key": "home_value"}`,
+			},
+			provenances: []string{"file:etc/config.json", "file:home/config.json"},
+			value:       "home_value",
+		},
+		{
+			name: "picks the first file found",
+			files: map[string]string{
+				"etc/config.json":  `{"string_key": "etc_value"}`,
+				"work/config.json": `{"string_key": "work_value"}`,
+			},
+			options:     []Option{WithFirstFound()},
+			provenances: []string{"file:etc/config.json"},
+			value:       "etc_value",
+		},
+		{
+			name: "selects the parser by extension",
+			files: map[string]string{
+				"etc/config.txt":  "text_value",
+				"etc/config.toml": "ignored",
+				"work/app.json":   `{"string_key": "work_value"}`,
+			},
+			options:     []Option{textParser, WithNames("config", "app")},
+			provenances: []string{"file:work/app.json", "file:etc/config.txt"},
+			value:       "text_value",
+		},
+		{
+			name:        "finds nothing",
+			files:       map[string]string{},
+			provenances: []string{},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				path, err := ioutil.TempDir("", "migi-discovery")
+				require.NoError(t, err)
+				defer os.RemoveAll(path)
+				for name, content := range scenario.files {
+					writeFile(t, filepath.Join(path, name), content)
+				}
+
+				options := append([]Option{
+					WithSearchPaths(
+						filepath.Join(path, "home"),
+						filepath.Join(path, "etc"),
+						filepath.Join(path, "work"),
+					),
+				}, scenario.options...)
+				sources, err := Find("app", options...)
+				require.NoError(t, err)
+
+				provenances := make([]string, len(sources))
+				for index, source := range sources {
+					provenance := source.(migi.Provenancer).Provenance()
+					provenances[index] = strings.Replace(provenance, path+string(filepath.Separator), "", 1)
+				}
+				assert.Equal(t, scenario.provenances, provenances)
+
+				if scenario.value == "" {
+					return
+				}
+				loaded := migi.NewOptions(sources...)
+				value := loaded.String("string_key", "", "")
+				require.NoError(t, loaded.Load())
+				assert.Equal(t, scenario.value, *value)
+			},
+		)
+	}
+}
+
+func TestFindMerge(t *testing.T) {
+	path, err := ioutil.TempDir("", "migi-discovery-merge")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "etc", "config.json"), `{"db": {"host": "etc.local", "port": 5432}}`)
+	writeFile(t, filepath.Join(path, "home", "config.json"), `{"db": {"host": "home.local"}}`)
+
+	sources, err := Find("app", WithSearchPaths(filepath.Join(path, "home"), filepath.Join(path, "etc")))
+	require.NoError(t, err)
+	for _, source := range sources {
+		require.NoError(t, source.Load())
+	}
+	db, err := migi.Merge(sources...).(migi.Valuer).Value("db")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"host": "home.local", "port": float64(5432)}, db)
+}
+
+func TestFindKeys(t *testing.T) {
+	path, err := ioutil.TempDir("", "migi-discovery-keys")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	writeFile(t, filepath.Join(path, "config.json"), `{"string_key": "string_value", "db": {"host": "db.local"}}`)
+
+	sources, err := Find("app", WithSearchPaths(path))
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	require.NoError(t, sources[0].Load())
+	assert.Equal(t, []string{"db", "string_key"}, sources[0].(migi.KeyLister).Keys())
+}
+
+// setenv sets the variable and returns the function restoring its previous value
+func setenv(t *testing.T, name string, value string) func() {
+	previous, set := os.LookupEnv(name)
+	require.NoError(t, os.Setenv(name, value))
+
+	return func() {
+		if set {
+			os.Setenv(name, previous)
+			return
+		}
+		os.Unsetenv(name)
+	}
+}
+
+func TestSearchPaths(t *testing.T) {
+	defer setenv(t, "XDG_CONFIG_HOME", "/xdg")()
+	defer setenv(t, "HOME", "/home/user")()
+	workingDir, err := os.Getwd()
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		[]string{"/xdg/app", "/home/user/.config/app", "/etc/app", workingDir},
+		SearchPaths("app"),
+	)
+
+	os.Setenv("XDG_CONFIG_HOME", "/home/user/.config/")
+	assert.Equal(t,
+		[]string{"/home/user/.config/app", "/etc/app", workingDir},
+		SearchPaths("app"),
+	)
+}
@@ -0,0 +1,16 @@
+package environment
+
+import (
+	"net/url"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("env", open)
+}
+
+// open creates the source of env://?prefix=APP_
+func open(u *url.URL) (migi.Source, error) {
+	return NewPrefixSource(u.Query().Get("prefix")), nil
+}
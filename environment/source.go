@@ -2,20 +2,43 @@ package environment
 
 import (
 	"os"
-	"strconv"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/parse"
 )
 
-type source struct{}
+type source struct {
+	prefix string
+}
 
 func (e *source) Load() error {
 	return nil
 }
 
+// Keys lists the names of the variables starting with the prefix, without it.
+// Sources without prefix list nothing, the whole environment is not theirs
+func (e *source) Keys() []string {
+	if e.prefix == "" {
+		return nil
+	}
+
+	var keys []string
+	for _, variable := range os.Environ() {
+		name := strings.SplitN(variable, "=", 2)[0]
+		if strings.HasPrefix(name, e.prefix) {
+			keys = append(keys, strings.TrimPrefix(name, e.prefix))
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 func (e *source) lookup(name string) (string, error) {
-	value, ok := os.LookupEnv(name)
+	value, ok := os.LookupEnv(e.prefix + name)
 	if !ok {
 		return "", migi.NewOptionNotFound(name)
 	}
@@ -38,12 +61,7 @@ func (e *source) Int(name string) (int, error) {
 		return 0, err
 	}
 
-	value, err := strconv.ParseInt(envValue, 10, 32)
-	if err != nil {
-		return 0, err
-	}
-
-	return int(value), nil
+	return parse.Int(envValue)
 }
 
 func (e *source) Float(name string) (float32, error) {
@@ -52,12 +70,7 @@ func (e *source) Float(name string) (float32, error) {
 		return 0, err
 	}
 
-	value, err := strconv.ParseFloat(envValue, 32)
-	if err != nil {
-		return 0, err
-	}
-
-	return float32(value), nil
+	return parse.Float(envValue)
 }
 
 func (e *source) Bool(name string) (bool, error) {
@@ -66,12 +79,7 @@ func (e *source) Bool(name string) (bool, error) {
 		return false, err
 	}
 
-	value, err := strconv.ParseBool(envValue)
-	if err != nil {
-		return false, err
-	}
-
-	return value, nil
+	return parse.Bool(envValue)
 }
 
 func (e *source) Time(name string) (time.Time, error) {
@@ -80,12 +88,7 @@ func (e *source) Time(name string) (time.Time, error) {
 		return time.Time{}, err
 	}
 
-	value, err := time.Parse(time.RFC3339, envValue)
-	if err != nil {
-		return time.Time{}, err
-	}
-
-	return value, nil
+	return parse.Time(envValue)
 }
 
 func (e *source) Duration(name string) (time.Duration, error) {
@@ -94,14 +97,14 @@ func (e *source) Duration(name string) (time.Duration, error) {
 		return time.Duration(0), err
 	}
 
-	value, err := time.ParseDuration(envValue)
-	if err != nil {
-		return time.Duration(0), err
-	}
-
-	return value, nil
+	return parse.Duration(envValue)
 }
 
 func NewSource() *source {
 	return new(source)
 }
+
+// NewPrefixSource creates an environment source looking options up as prefix followed by their names
+func NewPrefixSource(prefix string) *source {
+	return &source{prefix: prefix}
+}
@@ -114,3 +114,28 @@ func TestSource(t *testing.T) {
 		)
 	}
 }
+
+func TestPrefixSource(t *testing.T) {
+	os.Setenv("APP_string_key", "prefixed_value")
+	defer os.Unsetenv("APP_string_key")
+
+	source, err := migi.OpenSource("env://?prefix=APP_")
+	require.NoError(t, err)
+	require.NoError(t, source.Load())
+
+	value, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "prefixed_value", value)
+	_, err = source.String("APP_string_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+}
+
+func TestPrefixSourceKeys(t *testing.T) {
+	os.Setenv("MIGI_KEYS_string_key", "string_value")
+	defer os.Unsetenv("MIGI_KEYS_string_key")
+	os.Setenv("MIGI_KEYS_DB_HOST", "db.local")
+	defer os.Unsetenv("MIGI_KEYS_DB_HOST")
+
+	assert.Equal(t, []string{"DB_HOST", "string_key"}, NewPrefixSource("MIGI_KEYS_").Keys())
+	assert.Empty(t, NewSource().Keys())
+}
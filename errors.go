@@ -29,3 +29,119 @@ func (e OptionInvalidType) Error() string {
 func NewOptionInvalidType(name string, source interface{}, target string) error {
 	return OptionInvalidType{Name: name, Source: source, Target: target}
 }
+
+type FormatNotSupported struct {
+	Name string
+}
+
+func (e FormatNotSupported) Error() string {
+	return fmt.Sprintf("errors.FormatNotSupported{Name='%s'}", e.Name)
+}
+
+func NewFormatNotSupported(name string) error {
+	return FormatNotSupported{Name: name}
+}
+
+type OptionNotResolved struct {
+	Name   string
+	Scheme string
+	Cause  error
+}
+
+func (e OptionNotResolved) Error() string {
+	return fmt.Sprintf("errors.OptionNotResolved{Name='%s', Scheme='%s', Cause='%v'}", e.Name, e.Scheme, e.Cause)
+}
+
+func NewOptionNotResolved(name string, scheme string, cause error) error {
+	return OptionNotResolved{Name: name, Scheme: scheme, Cause: cause}
+}
+
+type OptionNotInterpolated struct {
+	Name  string
+	Cause error
+}
+
+func (e OptionNotInterpolated) Error() string {
+	return fmt.Sprintf("errors.OptionNotInterpolated{Name='%s', Cause='%v'}", e.Name, e.Cause)
+}
+
+func NewOptionNotInterpolated(name string, cause error) error {
+	return OptionNotInterpolated{Name: name, Cause: cause}
+}
+
+type SelectorInvalid struct {
+	Selector string
+	Term     string
+}
+
+func (e SelectorInvalid) Error() string {
+	return fmt.Sprintf("errors.SelectorInvalid{Selector='%s', Term='%s'}", e.Selector, e.Term)
+}
+
+func NewSelectorInvalid(selector string, term string) error {
+	return SelectorInvalid{Selector: selector, Term: term}
+}
+
+type SchemeNotRegistered struct {
+	Scheme string
+}
+
+func (e SchemeNotRegistered) Error() string {
+	return fmt.Sprintf("errors.SchemeNotRegistered{Scheme='%s'}", e.Scheme)
+}
+
+func NewSchemeNotRegistered(scheme string) error {
+	return SchemeNotRegistered{Scheme: scheme}
+}
+
+type OptionDeprecated struct {
+	Name       string
+	Deprecated string
+}
+
+func (e OptionDeprecated) Error() string {
+	return fmt.Sprintf("errors.OptionDeprecated{Name='%s', Deprecated='%s'}", e.Name, e.Deprecated)
+}
+
+func NewOptionDeprecated(name string, deprecated string) error {
+	return OptionDeprecated{Name: name, Deprecated: deprecated}
+}
+
+type OptionConflict struct {
+	Name       string
+	Deprecated string
+}
+
+func (e OptionConflict) Error() string {
+	return fmt.Sprintf("errors.OptionConflict{Name='%s', Deprecated='%s'}", e.Name, e.Deprecated)
+}
+
+func NewOptionConflict(name string, deprecated string) error {
+	return OptionConflict{Name: name, Deprecated: deprecated}
+}
+
+type OptionNotRegistered struct {
+	Name string
+}
+
+func (e OptionNotRegistered) Error() string {
+	return fmt.Sprintf("errors.OptionNotRegistered{Name='%s'}", e.Name)
+}
+
+func NewOptionNotRegistered(name string) error {
+	return OptionNotRegistered{Name: name}
+}
+
+type OptionUnknown struct {
+	Name       string
+	Provenance string
+	Suggestion string
+}
+
+func (e OptionUnknown) Error() string {
+	return fmt.Sprintf("errors.OptionUnknown{Name='%s', Provenance='%s', Suggestion='%s'}", e.Name, e.Provenance, e.Suggestion)
+}
+
+func NewOptionUnknown(name string, provenance string, suggestion string) error {
+	return OptionUnknown{Name: name, Provenance: provenance, Suggestion: suggestion}
+}
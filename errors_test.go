@@ -1,6 +1,7 @@
 package migi
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -21,3 +22,58 @@ func TestOptionInvalidOption(t *testing.T) {
 
 	assert.EqualError(t, err, "errors.OptionInvalidType{Name='my_option', Source='int', Target='string'}")
 }
+
+func TestFormatNotSupported(t *testing.T) {
+	name := "config.toml"
+	err := NewFormatNotSupported(name)
+
+	assert.EqualError(t, err, "errors.FormatNotSupported{Name='config.toml'}")
+}
+
+func TestOptionNotResolved(t *testing.T) {
+	err := NewOptionNotResolved("my_option", "file", errors.New("mock_error"))
+
+	assert.EqualError(t, err, "errors.OptionNotResolved{Name='my_option', Scheme='file', Cause='mock_error'}")
+}
+
+func TestOptionNotInterpolated(t *testing.T) {
+	err := NewOptionNotInterpolated("my_option", errors.New("mock_error"))
+
+	assert.EqualError(t, err, "errors.OptionNotInterpolated{Name='my_option', Cause='mock_error'}")
+}
+
+func TestSelectorInvalid(t *testing.T) {
+	err := NewSelectorInvalid("region=eu,zone", "zone")
+
+	assert.EqualError(t, err, "errors.SelectorInvalid{Selector='region=eu,zone', Term='zone'}")
+}
+
+func TestSchemeNotRegistered(t *testing.T) {
+	err := NewSchemeNotRegistered("zookeeper")
+
+	assert.EqualError(t, err, "errors.SchemeNotRegistered{Scheme='zookeeper'}")
+}
+
+func TestOptionDeprecated(t *testing.T) {
+	err := NewOptionDeprecated("http.timeout", "http_timeout")
+
+	assert.EqualError(t, err, "errors.OptionDeprecated{Name='http.timeout', Deprecated='http_timeout'}")
+}
+
+func TestOptionConflict(t *testing.T) {
+	err := NewOptionConflict("http.timeout", "http_timeout")
+
+	assert.EqualError(t, err, "errors.OptionConflict{Name='http.timeout', Deprecated='http_timeout'}")
+}
+
+func TestOptionNotRegistered(t *testing.T) {
+	err := NewOptionNotRegistered("http.timeout")
+
+	assert.EqualError(t, err, "errors.OptionNotRegistered{Name='http.timeout'}")
+}
+
+func TestOptionUnknown(t *testing.T) {
+	err := NewOptionUnknown("DATABSE_URL", "file:config.json", "DATABASE_URL")
+
+	assert.EqualError(t, err, "errors.OptionUnknown{Name='DATABSE_URL', Provenance='file:config.json', Suggestion='DATABASE_URL'}")
+}
@@ -0,0 +1,22 @@
+package etcd
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("etcd", open)
+}
+
+// open creates the source of etcd://host:2379/prefix/?tls=true
+func open(u *url.URL) (migi.Source, error) {
+	scheme := "http"
+	if tls, _ := strconv.ParseBool(u.Query().Get("tls")); tls {
+		scheme = "https"
+	}
+
+	return NewSource(scheme+"://"+u.Host, u.Path), nil
+}
@@ -0,0 +1,248 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rjansen/migi/httpsource"
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+// ErrWatchClosed is returned when the gateway ends a watch stream
+var ErrWatchClosed = errors.New("etcd: watch stream closed")
+
+type (
+	// Option customizes the etcd source
+	Option func(*source)
+
+	keyValue struct {
+		Key   []byte `json:"key"`
+		Value []byte `json:"value"`
+	}
+
+	header struct {
+		Revision string `json:"revision"`
+	}
+
+	rangeRequest struct {
+		Key      []byte `json:"key"`
+		RangeEnd []byte `json:"range_end"`
+	}
+
+	rangeResponse struct {
+		Header header     `json:"header"`
+		Kvs    []keyValue `json:"kvs"`
+	}
+
+	watchRequest struct {
+		CreateRequest struct {
+			rangeRequest
+			StartRevision string `json:"start_revision"`
+		} `json:"create_request"`
+	}
+
+	watchResponse struct {
+		Result struct {
+			Header header `json:"header"`
+			Events []struct {
+				Type string   `json:"type"`
+				Kv   keyValue `json:"kv"`
+			} `json:"events"`
+		} `json:"result"`
+	}
+
+	source struct {
+		*mapsource.Source
+		address string
+		prefix  string
+		client  *http.Client
+		// mutex guards values and revision, shared by Load and Watch
+		mutex    sync.Mutex
+		values   map[string]interface{}
+		revision int64
+	}
+)
+
+// WithClient replaces the default http client, which times out after httpsource.DefaultTimeout.
+// The client timeout applies to Load only, watches last until their context is done
+func WithClient(client *http.Client) Option {
+	return func(s *source) {
+		s.client = client
+	}
+}
+
+func (s *source) Load() error {
+	var response rangeResponse
+	body, err := s.post(context.Background(), s.client, "/v3/kv/range", s.keyRange())
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{}, len(response.Kvs))
+	for _, kv := range response.Kvs {
+		values[s.name(kv.Key)] = string(kv.Value)
+	}
+	revision, _ := strconv.ParseInt(response.Header.Revision, 10, 64)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.revision = revision
+	s.values = values
+	s.Set(s.snapshot())
+
+	return nil
+}
+
+// Keys lists the option names of the keys under the prefix, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+// Watch streams changes under the prefix since the last load, applying them
+// to the served values and calling changed after each batch, until ctx is done
+func (s *source) Watch(ctx context.Context, changed func()) error {
+	var request watchRequest
+	request.CreateRequest.rangeRequest = s.keyRange()
+	s.mutex.Lock()
+	request.CreateRequest.StartRevision = strconv.FormatInt(s.revision+1, 10)
+	s.mutex.Unlock()
+
+	// the watch streams until ctx is done, only the range requests time out
+	streaming := *s.client
+	streaming.Timeout = 0
+	body, err := s.post(ctx, &streaming, "/v3/watch", request)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer body.Close()
+
+	decoder := json.NewDecoder(body)
+	for {
+		var response watchResponse
+		if err := decoder.Decode(&response); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err == io.EOF {
+				return ErrWatchClosed
+			}
+			return err
+		}
+		if len(response.Result.Events) == 0 {
+			continue
+		}
+
+		s.apply(response)
+		if changed != nil {
+			changed()
+		}
+	}
+}
+
+// apply updates the served values with the events of a watch response
+func (s *source) apply(response watchResponse) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, event := range response.Result.Events {
+		if event.Type == "DELETE" {
+			delete(s.values, s.name(event.Kv.Key))
+			continue
+		}
+		s.values[s.name(event.Kv.Key)] = string(event.Kv.Value)
+	}
+	s.revision, _ = strconv.ParseInt(response.Result.Header.Revision, 10, 64)
+	s.Set(s.snapshot())
+}
+
+func (s *source) post(ctx context.Context, client *http.Client, path string, payload interface{}) (io.ReadCloser, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimSuffix(s.address, "/") + path
+	request, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, httpsource.NewStatusError(endpoint, response.StatusCode)
+	}
+
+	return response.Body, nil
+}
+
+// keyPrefix returns the prefix ending with a slash, etcd ranges match keys
+// by plain byte prefix so /app would also match /apple/key
+func (s *source) keyPrefix() string {
+	if s.prefix == "" || strings.HasSuffix(s.prefix, "/") {
+		return s.prefix
+	}
+
+	return s.prefix + "/"
+}
+
+// keyRange returns the range covering every key starting with the prefix
+func (s *source) keyRange() rangeRequest {
+	key := []byte(s.keyPrefix())
+	if len(key) == 0 {
+		// etcd requires a key, the range from \x00 to \x00 covers every key
+		return rangeRequest{Key: []byte{0}, RangeEnd: []byte{0}}
+	}
+	end := make([]byte, len(key))
+	copy(end, key)
+	end[len(end)-1]++
+
+	return rangeRequest{Key: key, RangeEnd: end}
+}
+
+func (s *source) name(key []byte) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(string(key), s.keyPrefix()), "/")
+	return strings.Replace(name, "/", ".", -1)
+}
+
+// snapshot copies the values, the mutex must be held
+func (s *source) snapshot() map[string]interface{} {
+	values := make(map[string]interface{}, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
+
+// NewSource creates a source that reads every key under prefix through the
+// etcd v3 json gateway at address, naming options by the key path joined by dots
+func NewSource(address string, prefix string, options ...Option) *source {
+	s := &source{
+		Source:  mapsource.New(nil),
+		address: address,
+		prefix:  prefix,
+		client:  &http.Client{Timeout: httpsource.DefaultTimeout},
+		values:  make(map[string]interface{}),
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
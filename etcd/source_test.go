@@ -0,0 +1,223 @@
+package etcd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/httpsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testGateway is a stand-in for the etcd v3 json gateway range and watch endpoints
+type testGateway struct {
+	mutex   sync.Mutex
+	kvs     map[string]string
+	events  []map[string]interface{}
+	ranges  []map[string]string
+	watches []map[string]map[string]string
+	// delay holds the watch events
+	delay time.Duration
+}
+
+func encode(value string) string {
+	return base64.StdEncoding.EncodeToString([]byte(value))
+}
+
+func decode(value string) string {
+	raw, _ := base64.StdEncoding.DecodeString(value)
+	return string(raw)
+}
+
+func (g *testGateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var request map[string]string
+		json.NewDecoder(r.Body).Decode(&request)
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		g.ranges = append(g.ranges, request)
+		var kvs []map[string]string
+		for key, value := range g.kvs {
+			end := decode(request["range_end"])
+			if key >= decode(request["key"]) && (key < end || end == "\x00") {
+				kvs = append(kvs, map[string]string{"key": encode(key), "value": encode(value)})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"header": map[string]string{"revision": "7"},
+			"kvs":    kvs,
+		})
+	case "/v3/watch":
+		var request map[string]map[string]string
+		json.NewDecoder(r.Body).Decode(&request)
+		g.mutex.Lock()
+		g.watches = append(g.watches, request)
+		g.mutex.Unlock()
+		encoder := json.NewEncoder(w)
+		encoder.Encode(map[string]interface{}{"result": map[string]interface{}{"created": true}})
+		w.(http.Flusher).Flush()
+		time.Sleep(g.delay)
+		for _, event := range g.events {
+			encoder.Encode(map[string]interface{}{
+				"result": map[string]interface{}{
+					"header": map[string]string{"revision": "8"},
+					"events": []interface{}{event},
+				},
+			})
+			w.(http.Flusher).Flush()
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestSource(t *testing.T) {
+	gateway := &testGateway{
+		kvs: map[string]string{
+			"/app/string_key": "string_value",
+			"/app/db/port":    "5432",
+			"/app/db/timeout": "5s",
+			"/other/key":      "other_value",
+		},
+		events: []map[string]interface{}{
+			{"kv": map[string]string{"key": encode("/app/string_key"), "value": encode("new_value")}},
+			{"type": "DELETE", "kv": map[string]string{"key": encode("/app/db/timeout")}},
+		},
+	}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	source := NewSource(server.URL, "/app/")
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	assert.Equal(t, "/app/", decode(gateway.ranges[0]["key"]))
+	assert.Equal(t, "/app0", decode(gateway.ranges[0]["range_end"]))
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	intValue, err := source.Int("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, intValue)
+	durationValue, err := source.Duration("db.timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second*5, durationValue)
+	_, err = source.String("other.key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+
+	changes := 0
+	err = source.Watch(context.Background(), func() { changes++ })
+	assert.Equal(t, ErrWatchClosed, err)
+	assert.Equal(t, 2, changes)
+	assert.Equal(t, "8", gateway.watches[0]["create_request"]["start_revision"])
+
+	stringValue, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "new_value", stringValue)
+	_, err = source.Duration("db.timeout")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+}
+
+func TestSourcePrefix(t *testing.T) {
+	gateway := &testGateway{
+		kvs: map[string]string{
+			"/app/string_key":   "string_value",
+			"/apple/string_key": "apple_value",
+		},
+	}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	source := NewSource(server.URL, "/app")
+	require.NoError(t, source.Load())
+	assert.Equal(t, "/app/", decode(gateway.ranges[0]["key"]))
+	assert.Equal(t, "/app0", decode(gateway.ranges[0]["range_end"]))
+	assert.Equal(t, []string{"string_key"}, source.Keys())
+
+	source = NewSource(server.URL, "")
+	require.NoError(t, source.Load())
+	assert.Equal(t, "\x00", decode(gateway.ranges[1]["key"]))
+	assert.Equal(t, "\x00", decode(gateway.ranges[1]["range_end"]))
+	assert.Equal(t, []string{"app.string_key", "apple.string_key"}, source.Keys())
+}
+
+func TestSourceWatchWhileLoading(t *testing.T) {
+	gateway := &testGateway{kvs: map[string]string{"/app/string_key": "string_value"}}
+	for index := 0; index < 100; index++ {
+		gateway.events = append(gateway.events, map[string]interface{}{
+			"kv": map[string]string{"key": encode(fmt.Sprintf("/app/key_%d", index)), "value": encode("value")},
+		})
+	}
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+
+	source := NewSource(server.URL, "/app/")
+	require.NoError(t, source.Load())
+
+	watched := make(chan error)
+	go func() {
+		watched <- source.Watch(context.Background(), nil)
+	}()
+	for index := 0; index < 10; index++ {
+		assert.NoError(t, source.Load())
+	}
+	assert.Equal(t, ErrWatchClosed, <-watched)
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+}
+
+func TestSourceWatchCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+	assert.NoError(t, NewSource(server.URL, "/app/").Watch(ctx, nil))
+}
+
+func TestSourceError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	assert.IsType(t, httpsource.StatusError{}, NewSource(server.URL, "/app/").Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("etcd://etcd.local:2379/app/")
+	require.NoError(t, err)
+	openedSource := opened.(*source)
+	assert.Equal(t, "http://etcd.local:2379", openedSource.address)
+	assert.Equal(t, "/app/", openedSource.prefix)
+}
+
+func TestSourceWatchTimeout(t *testing.T) {
+	server := httptest.NewServer(&testGateway{
+		kvs:    map[string]string{"/app/string_key": "string_value"},
+		events: []map[string]interface{}{{"kv": map[string]string{"key": encode("/app/string_key"), "value": encode("new_value")}}},
+		delay:  time.Millisecond * 150,
+	})
+	defer server.Close()
+
+	source := NewSource(server.URL, "/app/", WithClient(&http.Client{Timeout: time.Millisecond * 50}))
+	require.NoError(t, source.Load())
+	assert.Equal(t, ErrWatchClosed, source.Watch(context.Background(), nil))
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "new_value", stringValue)
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package execsource
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// isolate starts the command in its own process group
+func isolate(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// kill kills the process group of the command, with every process it forked
+func kill(command *exec.Cmd) {
+	syscall.Kill(-command.Process.Pid, syscall.SIGKILL)
+}
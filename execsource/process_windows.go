@@ -0,0 +1,13 @@
+package execsource
+
+import (
+	"os/exec"
+)
+
+// isolate is a no-op, windows has no process groups to kill at once
+func isolate(command *exec.Cmd) {}
+
+// kill kills the command process
+func kill(command *exec.Cmd) {
+	command.Process.Kill()
+}
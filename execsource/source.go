@@ -0,0 +1,165 @@
+package execsource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi/internal/mapsource"
+	"github.com/rjansen/migi/internal/parse"
+)
+
+// KeyPlaceholder is replaced by the option name in the arguments of a key source command
+const KeyPlaceholder = "{key}"
+
+type (
+	// Option customizes the exec source
+	Option func(*source)
+
+	source struct {
+		*mapsource.Source
+		command string
+		args    []string
+		keys    []string
+		timeout time.Duration
+		limit   int
+	}
+
+	// limitedBuffer keeps up to limit bytes and records whether more were written,
+	// still accepting them so the command never blocks on a full pipe
+	limitedBuffer struct {
+		buffer   bytes.Buffer
+		limit    int
+		exceeded bool
+	}
+)
+
+// WithTimeout limits the run time of each command, default 10s
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *source) {
+		s.timeout = timeout
+	}
+}
+
+// WithSizeLimit limits the size of the output of each command, default 1MiB
+func WithSizeLimit(limit int) Option {
+	return func(s *source) {
+		s.limit = limit
+	}
+}
+
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	if room := b.limit - b.buffer.Len(); room < len(data) {
+		b.exceeded = true
+		if room > 0 {
+			b.buffer.Write(data[:room])
+		}
+		return len(data), nil
+	}
+	return b.buffer.Write(data)
+}
+
+func (s *source) Load() error {
+	if s.keys == nil {
+		output, err := s.run(s.args)
+		if err != nil {
+			return err
+		}
+		values := make(map[string]interface{})
+		if err := json.Unmarshal(output, &values); err != nil {
+			return fmt.Errorf("execsource: %s output is not a json object", s.command)
+		}
+		s.Set(values)
+		return nil
+	}
+
+	values := make(map[string]interface{}, len(s.keys))
+	for _, key := range s.keys {
+		args := make([]string, len(s.args))
+		for index, arg := range s.args {
+			args[index] = strings.Replace(arg, KeyPlaceholder, key, -1)
+		}
+		output, err := s.run(args)
+		if err != nil {
+			return err
+		}
+		values[key] = parse.TrimNewline(string(output))
+	}
+	s.Set(values)
+
+	return nil
+}
+
+// Keys lists the option names read from the command output, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+// run executes the command returning its stdout. Errors never quote stdout,
+// which usually holds secrets, only stderr. On timeout the whole process group
+// is killed, as the processes forked by the command would keep stdout open
+func (s *source) run(args []string) ([]byte, error) {
+	var (
+		stdout = &limitedBuffer{limit: s.limit}
+		stderr = &limitedBuffer{limit: 4096}
+	)
+	command := exec.Command(s.command, args...)
+	command.Stdout = stdout
+	command.Stderr = stderr
+	isolate(command)
+	if err := command.Start(); err != nil {
+		return nil, fmt.Errorf("execsource: %s failed: %v", s.command, err)
+	}
+
+	timer := time.AfterFunc(s.timeout, func() {
+		kill(command)
+	})
+	err := command.Wait()
+	if !timer.Stop() {
+		return nil, fmt.Errorf("execsource: %s timed out after %s", s.command, s.timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("execsource: %s failed: %v: %s", s.command, err, strings.TrimSpace(stderr.buffer.String()))
+	}
+	if stdout.exceeded {
+		return nil, fmt.Errorf("execsource: %s output exceeds %d bytes", s.command, s.limit)
+	}
+
+	return stdout.buffer.Bytes(), nil
+}
+
+func newSource(command string, args []string, options ...Option) *source {
+	s := &source{
+		Source:  mapsource.New(nil),
+		command: command,
+		args:    args,
+		timeout: 10 * time.Second,
+		limit:   1 << 20,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// NewSource creates a source that runs command once and reads its output as a json object of options
+func NewSource(command string, args []string, options ...Option) *source {
+	return newSource(command, args, options...)
+}
+
+// NewKeySource creates a source that runs command once per key, replacing
+// KeyPlaceholder in args by the key, and serves its output, without the
+// trailing newline, as the key value. e.g. NewKeySource([]string{"db.password"}, "pass", []string{"show", "app/{key}"})
+func NewKeySource(keys []string, command string, args []string, options ...Option) *source {
+	s := newSource(command, args, options...)
+	s.keys = keys
+	if s.keys == nil {
+		s.keys = []string{}
+	}
+
+	return s
+}
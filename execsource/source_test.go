@@ -0,0 +1,71 @@
+package execsource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	source := NewSource("testdata/json.sh", nil)
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	intValue, err := source.Int("int_key")
+	assert.NoError(t, err)
+	assert.Equal(t, 333, intValue)
+	durationValue, err := source.Duration("duration_key")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute*5, durationValue)
+}
+
+func TestKeySource(t *testing.T) {
+	source := NewKeySource([]string{"db.password", "db.port"}, "testdata/pass.sh", []string{"show", "app/{key}"})
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("db.password")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", stringValue)
+	intValue, err := source.Int("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, intValue)
+	_, err = source.String("db.user")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+}
+
+func TestSourceError(t *testing.T) {
+	err := NewKeySource([]string{"db.user"}, "testdata/pass.sh", []string{"show", "app/{key}"}).Load()
+	assert.EqualError(t, err, "execsource: testdata/pass.sh failed: exit status 1: Error: app/db.user is not in the password store.")
+
+	err = NewSource("testdata/slow.sh", nil, WithTimeout(time.Millisecond*50)).Load()
+	assert.EqualError(t, err, "execsource: testdata/slow.sh timed out after 50ms")
+
+	started := time.Now()
+	err = NewSource("testdata/fork.sh", nil, WithTimeout(time.Millisecond*100)).Load()
+	assert.EqualError(t, err, "execsource: testdata/fork.sh timed out after 100ms")
+	assert.True(t, time.Since(started) < time.Second, "the forked processes must be killed")
+
+	err = NewSource("testdata/big.sh", nil, WithSizeLimit(1024)).Load()
+	assert.EqualError(t, err, "execsource: testdata/big.sh output exceeds 1024 bytes")
+
+	err = NewSource("testdata/pass.sh", []string{"show", "app/db.password"}).Load()
+	assert.EqualError(t, err, "execsource: testdata/pass.sh output is not a json object")
+
+	assert.Error(t, NewSource("testdata/missing.sh", nil).Load())
+}
+
+func TestSourceKeys(t *testing.T) {
+	source := NewSource("testdata/json.sh", nil)
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"duration_key", "int_key", "string_key"}, source.Keys())
+
+	source = NewKeySource([]string{"db.password"}, "testdata/pass.sh", []string{"show", "app/{key}"})
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"db.password"}, source.Keys())
+}
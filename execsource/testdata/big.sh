@@ -0,0 +1,6 @@
+#!/bin/sh
+i=0
+while [ $i -lt 200 ]; do
+  echo "0123456789012345678901234567890123456789"
+  i=$((i + 1))
+done
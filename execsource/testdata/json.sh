@@ -0,0 +1,2 @@
+#!/bin/sh
+echo '{"string_key": "string_value", "int_key": 333, "duration_key": "5m"}'
@@ -0,0 +1,7 @@
+#!/bin/sh
+# prints the secret stored for the key, like `pass show app/<key>`
+case "$2" in
+  app/db.password) echo "s3cr3t" ;;
+  app/db.port) echo "5432" ;;
+  *) echo "Error: $2 is not in the password store." >&2; exit 1 ;;
+esac
@@ -0,0 +1,31 @@
+package gitsource
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("git", open)
+}
+
+// open creates the source of git:///srv/config.git?ref=v1.2.0&file=config/prod.json
+func open(u *url.URL) (migi.Source, error) {
+	query := u.Query()
+	if query.Get("file") == "" {
+		return nil, errors.New("gitsource: the file query parameter is required")
+	}
+	ref := query.Get("ref")
+	if ref == "" {
+		ref = "HEAD"
+	}
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+
+	return NewSource(filepath.FromSlash(path), ref, query.Get("file")), nil
+}
@@ -0,0 +1,458 @@
+package gitsource
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	objectCommit   = 1
+	objectTree     = 2
+	objectBlob     = 3
+	objectTag      = 4
+	objectOfsDelta = 6
+	objectRefDelta = 7
+)
+
+var objectTypes = map[string]int{
+	"commit": objectCommit,
+	"tree":   objectTree,
+	"blob":   objectBlob,
+	"tag":    objectTag,
+}
+
+// repository reads refs and objects straight from a git directory
+type repository struct {
+	path string
+}
+
+func openRepository(path string) (*repository, error) {
+	gitDir := filepath.Join(path, ".git")
+	if info, err := os.Stat(gitDir); err == nil && info.IsDir() {
+		path = gitDir
+	}
+	if _, err := os.Stat(filepath.Join(path, "objects")); err != nil {
+		return nil, fmt.Errorf("gitsource: %s is not a git repository: %v", path, err)
+	}
+
+	return &repository{path: path}, nil
+}
+
+// resolve returns the commit hash a ref name, abbreviated ref or full hash points to
+func (r *repository) resolve(ref string) (string, error) {
+	hash, err := r.resolveRef(ref, 0)
+	if err != nil {
+		return "", err
+	}
+
+	// peel annotated tags down to the commit they point to
+	for {
+		objectType, data, err := r.object(hash)
+		if err != nil {
+			return "", err
+		}
+		if objectType != objectTag {
+			if objectType != objectCommit {
+				return "", fmt.Errorf("gitsource: ref %s does not point to a commit", ref)
+			}
+			return hash, nil
+		}
+		hash, err = hashHeader(data, "object")
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (r *repository) resolveRef(ref string, depth int) (string, error) {
+	if depth > 5 {
+		return "", fmt.Errorf("gitsource: too many symbolic ref levels for %s", ref)
+	}
+	if isHash(ref) {
+		return ref, nil
+	}
+
+	packed, err := r.packedRefs()
+	if err != nil {
+		return "", err
+	}
+	candidates := []string{"refs/" + ref, "refs/tags/" + ref, "refs/heads/" + ref, "refs/remotes/" + ref}
+	if ref == "HEAD" || strings.HasPrefix(ref, "refs/") {
+		candidates = []string{ref}
+	}
+	for _, candidate := range candidates {
+		content, err := ioutil.ReadFile(filepath.Join(r.path, filepath.FromSlash(candidate)))
+		if err == nil {
+			value := strings.TrimSpace(string(content))
+			if strings.HasPrefix(value, "ref: ") {
+				return r.resolveRef(strings.TrimPrefix(value, "ref: "), depth+1)
+			}
+			if !isHash(value) {
+				return "", fmt.Errorf("gitsource: ref %s holds an invalid object hash %q", candidate, value)
+			}
+			return value, nil
+		}
+		if hash, ok := packed[candidate]; ok {
+			if !isHash(hash) {
+				return "", fmt.Errorf("gitsource: packed ref %s holds an invalid object hash %q", candidate, hash)
+			}
+			return hash, nil
+		}
+	}
+
+	return "", fmt.Errorf("gitsource: ref %s not found", ref)
+}
+
+func (r *repository) packedRefs() (map[string]string, error) {
+	refs := make(map[string]string)
+	content, err := ioutil.ReadFile(filepath.Join(r.path, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return refs, nil
+		}
+		return nil, err
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "^") {
+			refs[fields[1]] = fields[0]
+		}
+	}
+
+	return refs, nil
+}
+
+// file returns the content of the blob at path in the tree of commit
+func (r *repository) file(commit string, path string) ([]byte, error) {
+	_, data, err := r.object(commit)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := hashHeader(data, "tree")
+	if err != nil {
+		return nil, err
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for index, segment := range segments {
+		objectType, data, err := r.object(hash)
+		if err != nil {
+			return nil, err
+		}
+		if objectType != objectTree {
+			return nil, fmt.Errorf("gitsource: %s is not a directory", strings.Join(segments[:index], "/"))
+		}
+		if hash, err = treeEntry(data, segment); err != nil {
+			return nil, fmt.Errorf("gitsource: %s not found at %s", path, commit)
+		}
+	}
+
+	objectType, data, err := r.object(hash)
+	if err != nil {
+		return nil, err
+	}
+	if objectType != objectBlob {
+		return nil, fmt.Errorf("gitsource: %s is not a file", path)
+	}
+
+	return data, nil
+}
+
+// object reads an object from the loose objects or any pack
+func (r *repository) object(hash string) (int, []byte, error) {
+	if !isHash(hash) {
+		return 0, nil, fmt.Errorf("gitsource: invalid object hash %q", hash)
+	}
+	objectType, data, err := r.looseObject(hash)
+	if err == nil || !os.IsNotExist(err) {
+		return objectType, data, err
+	}
+
+	packs, err := filepath.Glob(filepath.Join(r.path, "objects", "pack", "*.idx"))
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, index := range packs {
+		offset, found, err := findInIndex(index, hash)
+		if err != nil {
+			return 0, nil, err
+		}
+		if found {
+			return r.packedObject(strings.TrimSuffix(index, ".idx")+".pack", offset)
+		}
+	}
+
+	return 0, nil, fmt.Errorf("gitsource: object %s not found", hash)
+}
+
+func (r *repository) looseObject(hash string) (int, []byte, error) {
+	file, err := os.Open(filepath.Join(r.path, "objects", hash[:2], hash[2:]))
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	reader, err := zlib.NewReader(file)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer reader.Close()
+	raw, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	nul := bytes.IndexByte(raw, 0)
+	if nul < 0 {
+		return 0, nil, fmt.Errorf("gitsource: invalid object %s", hash)
+	}
+	fields := strings.Fields(string(raw[:nul]))
+	if len(fields) != 2 {
+		return 0, nil, fmt.Errorf("gitsource: invalid object %s", hash)
+	}
+	objectType, ok := objectTypes[fields[0]]
+	if !ok {
+		return 0, nil, fmt.Errorf("gitsource: invalid object type %s", fields[0])
+	}
+
+	return objectType, raw[nul+1:], nil
+}
+
+// findInIndex looks hash up in a version 2 pack index
+func findInIndex(path string, hash string) (int64, bool, error) {
+	index, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(index) < 8+256*4 || !bytes.Equal(index[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(index[4:8]) != 2 {
+		return 0, false, fmt.Errorf("gitsource: unsupported pack index %s", path)
+	}
+	name, err := hex.DecodeString(hash)
+	if err != nil {
+		return 0, false, err
+	}
+
+	fanout := index[8 : 8+256*4]
+	count := int(binary.BigEndian.Uint32(fanout[255*4:]))
+	namesStart := 8 + 256*4
+	crcStart := namesStart + count*20
+	offsetsStart := crcStart + count*4
+	largeOffsetsStart := offsetsStart + count*4
+
+	position := sort.Search(count, func(i int) bool {
+		return bytes.Compare(index[namesStart+i*20:namesStart+(i+1)*20], name) >= 0
+	})
+	if position == count || !bytes.Equal(index[namesStart+position*20:namesStart+(position+1)*20], name) {
+		return 0, false, nil
+	}
+
+	offset := binary.BigEndian.Uint32(index[offsetsStart+position*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true, nil
+	}
+	large := largeOffsetsStart + int(offset&0x7fffffff)*8
+
+	return int64(binary.BigEndian.Uint64(index[large:])), true, nil
+}
+
+// packedObject reads the object at offset in a pack, applying deltas
+func (r *repository) packedObject(path string, offset int64) (int, []byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, nil, err
+	}
+	reader := bufio.NewReader(file)
+
+	b, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	objectType := int(b>>4) & 7
+	size := int64(b & 0x0f)
+	for shift := uint(4); b&0x80 != 0; shift += 7 {
+		if b, err = reader.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+		size |= int64(b&0x7f) << shift
+	}
+
+	var (
+		baseType int
+		base     []byte
+	)
+	switch objectType {
+	case objectOfsDelta:
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		distance := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = reader.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			distance = ((distance + 1) << 7) | int64(b&0x7f)
+		}
+		if baseType, base, err = r.packedObject(path, offset-distance); err != nil {
+			return 0, nil, err
+		}
+	case objectRefDelta:
+		name := make([]byte, 20)
+		if _, err := io.ReadFull(reader, name); err != nil {
+			return 0, nil, err
+		}
+		if baseType, base, err = r.object(hex.EncodeToString(name)); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	inflater, err := zlib.NewReader(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer inflater.Close()
+	data := make([]byte, size)
+	if _, err := io.ReadFull(inflater, data); err != nil {
+		return 0, nil, err
+	}
+
+	if base == nil {
+		return objectType, data, nil
+	}
+	target, err := applyDelta(base, data)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return baseType, target, nil
+}
+
+func applyDelta(base []byte, delta []byte) ([]byte, error) {
+	position := 0
+	varint := func() int {
+		value, shift := 0, uint(0)
+		for position < len(delta) {
+			b := delta[position]
+			position++
+			value |= int(b&0x7f) << shift
+			shift += 7
+			if b&0x80 == 0 {
+				break
+			}
+		}
+		return value
+	}
+
+	if varint() != len(base) {
+		return nil, fmt.Errorf("gitsource: delta base size mismatch")
+	}
+	target := make([]byte, 0, varint())
+	for position < len(delta) {
+		op := delta[position]
+		position++
+		if op&0x80 == 0 {
+			if op == 0 || position+int(op) > len(delta) {
+				return nil, fmt.Errorf("gitsource: invalid delta instruction")
+			}
+			target = append(target, delta[position:position+int(op)]...)
+			position += int(op)
+			continue
+		}
+
+		var copyOffset, copySize int
+		for i := uint(0); i < 4; i++ {
+			if op&(1<<i) != 0 && position < len(delta) {
+				copyOffset |= int(delta[position]) << (8 * i)
+				position++
+			}
+		}
+		for i := uint(0); i < 3; i++ {
+			if op&(1<<(4+i)) != 0 && position < len(delta) {
+				copySize |= int(delta[position]) << (8 * i)
+				position++
+			}
+		}
+		if copySize == 0 {
+			copySize = 0x10000
+		}
+		if copyOffset+copySize > len(base) {
+			return nil, fmt.Errorf("gitsource: delta copy out of range")
+		}
+		target = append(target, base[copyOffset:copyOffset+copySize]...)
+	}
+	if len(target) != cap(target) {
+		return nil, fmt.Errorf("gitsource: delta target size mismatch")
+	}
+
+	return target, nil
+}
+
+// header returns the value of the first line starting with name in a commit or tag
+func header(data []byte, name string) (string, error) {
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		if strings.HasPrefix(line, name+" ") {
+			return strings.TrimPrefix(line, name+" "), nil
+		}
+	}
+
+	return "", fmt.Errorf("gitsource: object header %s not found", name)
+}
+
+// hashHeader returns the object hash held by the first line starting with name in a commit or tag
+func hashHeader(data []byte, name string) (string, error) {
+	hash, err := header(data, name)
+	if err != nil {
+		return "", err
+	}
+	if !isHash(hash) {
+		return "", fmt.Errorf("gitsource: object header %s holds an invalid object hash %q", name, hash)
+	}
+
+	return hash, nil
+}
+
+// isHash reports whether value is a full, hex encoded, object hash
+func isHash(value string) bool {
+	if len(value) != 40 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+
+	return err == nil
+}
+
+// treeEntry returns the hash of the entry called name in a tree object
+func treeEntry(data []byte, name string) (string, error) {
+	for len(data) > 0 {
+		nul := bytes.IndexByte(data, 0)
+		if nul < 0 || nul+21 > len(data) {
+			break
+		}
+		entry := string(data[:nul])
+		space := strings.IndexByte(entry, ' ')
+		if space >= 0 && entry[space+1:] == name {
+			return hex.EncodeToString(data[nul+1 : nul+21]), nil
+		}
+		data = data[nul+21:]
+	}
+
+	return "", fmt.Errorf("gitsource: tree entry %s not found", name)
+}
@@ -0,0 +1,75 @@
+package gitsource
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/format"
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+type source struct {
+	migi.Source
+	repository string
+	ref        string
+	path       string
+	commit     string
+}
+
+func (s *source) Load() error {
+	repository, err := openRepository(s.repository)
+	if err != nil {
+		return err
+	}
+	commit, err := repository.resolve(s.ref)
+	if err != nil {
+		return err
+	}
+	content, err := repository.file(commit, s.path)
+	if err != nil {
+		return err
+	}
+
+	parser, err := format.NewSource(s.path, "", bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	if err := parser.Load(); err != nil {
+		return err
+	}
+	s.Source = parser
+	s.commit = commit
+
+	return nil
+}
+
+// Commit returns the hash of the commit the options were loaded from
+func (s *source) Commit() string {
+	return s.commit
+}
+
+// Keys lists the option names of the parsed file, see migi.KeyLister
+func (s *source) Keys() []string {
+	if lister, is := s.Source.(migi.KeyLister); is {
+		return lister.Keys()
+	}
+
+	return nil
+}
+
+func (s *source) Provenance() string {
+	return fmt.Sprintf("git:%s@%s:%s", s.repository, s.commit, s.path)
+}
+
+// NewSource creates a source that reads the file at path, as of ref, from the
+// git repository, working tree or bare, at repository. The parser is chosen
+// by the file extension
+func NewSource(repository string, ref string, path string) *source {
+	return &source{
+		Source:     mapsource.New(nil),
+		repository: repository,
+		ref:        ref,
+		path:       path,
+	}
+}
@@ -0,0 +1,180 @@
+package gitsource
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testRepository = "testdata/repo.git"
+
+type (
+	testSource struct {
+		name  string
+		ref   string
+		match testSourceMatch
+	}
+
+	testSourceMatch struct {
+		commit  string
+		options map[string]interface{}
+	}
+)
+
+func TestSource(t *testing.T) {
+	scenarios := []testSource{
+		{
+			name: "load loose objects from HEAD",
+			ref:  "HEAD",
+			match: testSourceMatch{
+				commit:  "59389cf366ef7fc18786bc9d4d40a81d0265df73",
+				options: map[string]interface{}{"string_key": "v3_value", "int_key": 3},
+			},
+		},
+		{
+			name: "load loose objects from branch",
+			ref:  "master",
+			match: testSourceMatch{
+				commit:  "59389cf366ef7fc18786bc9d4d40a81d0265df73",
+				options: map[string]interface{}{"string_key": "v3_value", "int_key": 3},
+			},
+		},
+		{
+			name: "load packed objects from annotated tag",
+			ref:  "v1",
+			match: testSourceMatch{
+				commit:  "616d2853713021d659193c96afb9d699f287507b",
+				options: map[string]interface{}{"string_key": "v1_value", "int_key": 1},
+			},
+		},
+		{
+			name: "load packed delta objects from lightweight tag",
+			ref:  "refs/tags/v2",
+			match: testSourceMatch{
+				commit:  "50e0b1935b5d06628222e9d9a31cbed19698d240",
+				options: map[string]interface{}{"string_key": "v2_value", "int_key": 2},
+			},
+		},
+		{
+			name: "load packed objects from commit hash",
+			ref:  "616d2853713021d659193c96afb9d699f287507b",
+			match: testSourceMatch{
+				commit:  "616d2853713021d659193c96afb9d699f287507b",
+				options: map[string]interface{}{"string_key": "v1_value", "int_key": 1},
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				source := NewSource(testRepository, scenario.ref, "config/prod.json")
+				require.Implements(t, (*migi.Source)(nil), source)
+				require.Implements(t, (*migi.Provenancer)(nil), source)
+				require.NoError(t, source.Load())
+
+				assert.Equal(t, scenario.match.commit, source.Commit())
+				assert.Equal(t, "git:"+testRepository+"@"+scenario.match.commit+":config/prod.json", source.Provenance())
+				for key, value := range scenario.match.options {
+					switch value.(type) {
+					case string:
+						v, err := source.String(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					case int:
+						v, err := source.Int(key)
+						assert.Nil(t, err)
+						assert.Equal(t, value, v)
+					}
+				}
+				durationValue, err := source.Duration("duration_key")
+				assert.NoError(t, err)
+				assert.Equal(t, time.Minute*5, durationValue)
+			},
+		)
+	}
+}
+
+func TestSourceError(t *testing.T) {
+	_, err := NewSource(testRepository, "HEAD", "config/prod.json").String("string_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+
+	assert.Error(t, NewSource("testdata", "HEAD", "config/prod.json").Load())
+	assert.EqualError(t, NewSource(testRepository, "v9", "config/prod.json").Load(), "gitsource: ref v9 not found")
+	assert.Error(t, NewSource(testRepository, "HEAD", "config/missing.json").Load())
+	assert.Error(t, NewSource(testRepository, "HEAD", "config").Load())
+}
+
+// writeObject stores a loose object in the git directory at path and returns its hash
+func writeObject(t *testing.T, path string, objectType string, content string) string {
+	data := []byte(fmt.Sprintf("%s %d\x00%s", objectType, len(content), content))
+	hash := fmt.Sprintf("%x", sha1.Sum(data))
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, err := writer.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	writeFile(t, filepath.Join(path, "objects", hash[:2], hash[2:]), compressed.String())
+
+	return hash
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestSourceMalformedRepository(t *testing.T) {
+	path, err := ioutil.TempDir("", "migi-gitsource")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	commit := writeObject(t, path, "commit", "tree x\nauthor a <a@local> 0 +0000\n\nbroken\n")
+	tag := writeObject(t, path, "tag", "object "+commit[:7]+"\ntype commit\ntag broken\n\nbroken\n")
+	writeFile(t, filepath.Join(path, "refs", "heads", "empty"), "\n")
+	writeFile(t, filepath.Join(path, "refs", "heads", "truncated"), "a")
+	writeFile(t, filepath.Join(path, "refs", "heads", "commit"), commit)
+	writeFile(t, filepath.Join(path, "refs", "tags", "tag"), tag)
+	writeFile(t, filepath.Join(path, "packed-refs"), "x refs/heads/packed\n")
+
+	for ref, message := range map[string]string{
+		"empty":     `gitsource: ref refs/heads/empty holds an invalid object hash ""`,
+		"truncated": `gitsource: ref refs/heads/truncated holds an invalid object hash "a"`,
+		"packed":    `gitsource: packed ref refs/heads/packed holds an invalid object hash "x"`,
+		"commit":    `gitsource: object header tree holds an invalid object hash "x"`,
+		"tag":       `gitsource: object header object holds an invalid object hash "` + commit[:7] + `"`,
+	} {
+		assert.EqualError(t, NewSource(path, ref, "config/prod.json").Load(), message, ref)
+	}
+}
+
+func TestOpenSource(t *testing.T) {
+	source, err := migi.OpenSource("git:" + testRepository + "?ref=v1&file=config/prod.json")
+	require.NoError(t, err)
+	require.NoError(t, source.Load())
+	assert.Equal(t, "616d2853713021d659193c96afb9d699f287507b", source.(interface{ Commit() string }).Commit())
+
+	_, err = migi.OpenSource("git:" + testRepository)
+	assert.EqualError(t, err, "gitsource: the file query parameter is required")
+}
+
+func TestSourceKeys(t *testing.T) {
+	source := NewSource(testRepository, "v1", "config/prod.json")
+	require.NoError(t, source.Load())
+	keys := source.Keys()
+	assert.Contains(t, keys, "string_key")
+	assert.Contains(t, keys, "int_key")
+}
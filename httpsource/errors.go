@@ -0,0 +1,18 @@
+package httpsource
+
+import (
+	"fmt"
+)
+
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("httpsource.StatusError{URL='%s', StatusCode='%d'}", e.URL, e.StatusCode)
+}
+
+func NewStatusError(url string, statusCode int) error {
+	return StatusError{URL: url, StatusCode: statusCode}
+}
@@ -0,0 +1,17 @@
+package httpsource
+
+import (
+	"net/url"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("http", open)
+	migi.RegisterSource("https", open)
+}
+
+// open creates the source of the http or https url itself
+func open(u *url.URL) (migi.Source, error) {
+	return NewSource(u.String()), nil
+}
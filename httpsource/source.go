@@ -0,0 +1,131 @@
+package httpsource
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+// DefaultTimeout limits every request of the default http client of the
+// http based sources, replace the client with WithClient to change it
+const DefaultTimeout = time.Second * 30
+
+type (
+	// Option customizes the http source
+	Option func(*source)
+
+	source struct {
+		*mapsource.Source
+		url     string
+		client  *http.Client
+		header  http.Header
+		etag    string
+		expires time.Time
+		now     func() time.Time
+	}
+)
+
+// WithHeader adds a header, like Authorization, to every request
+func WithHeader(key string, value string) Option {
+	return func(s *source) {
+		s.header.Add(key, value)
+	}
+}
+
+// WithClient replaces the default http client, which times out after DefaultTimeout
+func WithClient(client *http.Client) Option {
+	return func(s *source) {
+		s.client = client
+	}
+}
+
+func (s *source) Load() error {
+	if s.now().Before(s.expires) {
+		return nil
+	}
+
+	request, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range s.header {
+		request.Header[key] = values
+	}
+	request.Header.Set("Accept", "application/json")
+	if s.etag != "" {
+		request.Header.Set("If-None-Match", s.etag)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		s.expires = s.expiration(response.Header)
+		return nil
+	case http.StatusOK:
+	default:
+		return NewStatusError(s.url, response.StatusCode)
+	}
+
+	values := make(map[string]interface{})
+	if err := json.NewDecoder(response.Body).Decode(&values); err != nil {
+		return err
+	}
+	s.Set(values)
+	s.etag = response.Header.Get("ETag")
+	s.expires = s.expiration(response.Header)
+
+	return nil
+}
+
+// Keys lists the option names of the loaded json object, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+// expiration returns until when a response may be served without revalidation
+func (s *source) expiration(header http.Header) time.Time {
+	var maxAge time.Duration
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-cache", directive == "no-store":
+			return time.Time{}
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil {
+				return time.Time{}
+			}
+			maxAge = time.Duration(seconds) * time.Second
+		}
+	}
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+
+	return s.now().Add(maxAge)
+}
+
+// NewSource creates a source that loads a json object of options from url
+func NewSource(url string, options ...Option) *source {
+	s := &source{
+		Source: mapsource.New(nil),
+		url:    url,
+		client: &http.Client{Timeout: DefaultTimeout},
+		header: make(http.Header),
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
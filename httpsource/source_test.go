@@ -0,0 +1,155 @@
+package httpsource
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testServer struct {
+	body         string
+	etag         string
+	cacheControl string
+	statusCode   int
+	requests     int
+	notModified  int
+	headers      []http.Header
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests++
+	s.headers = append(s.headers, r.Header)
+	if s.statusCode != 0 {
+		w.WriteHeader(s.statusCode)
+		return
+	}
+	if s.cacheControl != "" {
+		w.Header().Set("Cache-Control", s.cacheControl)
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+		if r.Header.Get("If-None-Match") == s.etag {
+			s.notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(s.body))
+}
+
+func TestSource(t *testing.T) {
+	handler := &testServer{
+		body: `{"string_key": "string_value", "int_key": 333, "duration_key": "5m"}`,
+		etag: `"v1"`,
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	source := NewSource(server.URL, WithHeader("Authorization", "Bearer token"))
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	intValue, err := source.Int("int_key")
+	assert.NoError(t, err)
+	assert.Equal(t, 333, intValue)
+	durationValue, err := source.Duration("duration_key")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute*5, durationValue)
+	_, err = source.String("missing_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+
+	require.NoError(t, source.Load())
+	assert.Equal(t, 2, handler.requests)
+	assert.Equal(t, 1, handler.notModified)
+	assert.Equal(t, `"v1"`, handler.headers[1].Get("If-None-Match"))
+	assert.Equal(t, "Bearer token", handler.headers[1].Get("Authorization"))
+
+	stringValue, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+
+	handler.etag = `"v2"`
+	handler.body = `{"string_key": "new_value"}`
+	require.NoError(t, source.Load())
+	assert.Equal(t, 3, handler.requests)
+	stringValue, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "new_value", stringValue)
+}
+
+func TestSourceCacheControl(t *testing.T) {
+	handler := &testServer{
+		body:         `{"string_key": "string_value"}`,
+		cacheControl: "public, max-age=60",
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	now := time.Now()
+	source := NewSource(server.URL)
+	source.now = func() time.Time { return now }
+
+	require.NoError(t, source.Load())
+	require.NoError(t, source.Load())
+	assert.Equal(t, 1, handler.requests)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, source.Load())
+	assert.Equal(t, 2, handler.requests)
+
+	handler.cacheControl = "no-cache"
+	now = now.Add(time.Minute)
+	require.NoError(t, source.Load())
+	require.NoError(t, source.Load())
+	assert.Equal(t, 4, handler.requests)
+}
+
+func TestSourceError(t *testing.T) {
+	handler := &testServer{statusCode: http.StatusForbidden}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	source := NewSource(server.URL)
+	err := source.Load()
+	assert.EqualError(t, err, NewStatusError(server.URL, http.StatusForbidden).Error())
+
+	handler.statusCode = 0
+	handler.body = `{invalid`
+	assert.Error(t, source.Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("https://config.local/app.json?env=prod")
+	require.NoError(t, err)
+	assert.Equal(t, "https://config.local/app.json?env=prod", opened.(*source).url)
+}
+
+func TestSourceKeys(t *testing.T) {
+	server := httptest.NewServer(&testServer{body: `{"string_key": "string_value", "db.host": "db.local"}`})
+	defer server.Close()
+
+	source := NewSource(server.URL)
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"db.host", "string_key"}, source.Keys())
+}
+
+func TestSourceTimeout(t *testing.T) {
+	assert.Equal(t, DefaultTimeout, NewSource("http://config.local").client.Timeout)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 200)
+	}))
+	defer server.Close()
+
+	source := NewSource(server.URL, WithClient(&http.Client{Timeout: time.Millisecond * 50}))
+	assert.Error(t, source.Load())
+}
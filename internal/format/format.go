@@ -0,0 +1,28 @@
+package format
+
+import (
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/rjansen/migi"
+	jsonsource "github.com/rjansen/migi/json"
+)
+
+// NewSource creates the source able to parse the document read from reader,
+// chosen by its content type or, when it is empty or generic, by the extension of name
+func NewSource(name string, contentType string, reader io.Reader) (migi.Source, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/json", strings.HasSuffix(mediaType, "+json"):
+		return jsonsource.NewSource(reader), nil
+	}
+
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return jsonsource.NewSource(reader), nil
+	}
+
+	return nil, migi.NewFormatNotSupported(name)
+}
@@ -0,0 +1,30 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rjansen/migi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSource(t *testing.T) {
+	for _, scenario := range [][2]string{
+		{"config/prod.json", ""},
+		{"config/prod.JSON", "application/octet-stream"},
+		{"bundle", "application/json; charset=utf-8"},
+		{"bundle", "application/vnd.config+json"},
+	} {
+		source, err := NewSource(scenario[0], scenario[1], strings.NewReader(`{"string_key": "string_value"}`))
+		require.NoError(t, err, scenario[0])
+		require.NoError(t, source.Load())
+
+		value, err := source.String("string_key")
+		assert.NoError(t, err)
+		assert.Equal(t, "string_value", value)
+	}
+
+	_, err := NewSource("config/prod.toml", "", strings.NewReader(""))
+	assert.Equal(t, migi.NewFormatNotSupported("config/prod.toml"), err)
+}
@@ -0,0 +1,168 @@
+package mapsource
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/parse"
+)
+
+// Source serves options from an in memory map of raw values.
+// String values are parsed the same way the environment source does
+type Source struct {
+	mutex  sync.RWMutex
+	values map[string]interface{}
+}
+
+// Load is a no-op, sources embedding Source must fill it with Set
+func (s *Source) Load() error {
+	return nil
+}
+
+// Set replaces the values served by the source
+func (s *Source) Set(values map[string]interface{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.values = values
+}
+
+// Lookup returns the raw value for the provided option name
+func (s *Source) Lookup(name string) (interface{}, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	value, ok := s.values[name]
+	if !ok {
+		return nil, migi.NewOptionNotFound(name)
+	}
+
+	return value, nil
+}
+
+// Names lists the sorted names of the values. Sources embedding Source
+// implement migi.KeyLister with it only when they hold config options alone
+func (s *Source) Names() []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	keys := make([]string, 0, len(s.values))
+	for key := range s.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Value returns the raw value for the provided option name, see migi.Valuer
+func (s *Source) Value(name string) (interface{}, error) {
+	return s.Lookup(name)
+}
+
+func (s *Source) String(name string) (string, error) {
+	value, err := s.Lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	strValue, is := value.(string)
+	if !is {
+		return "", migi.NewOptionInvalidType(name, value, "string")
+	}
+
+	return strValue, nil
+}
+
+func (s *Source) Int(name string) (int, error) {
+	value, err := s.Lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch rawValue := value.(type) {
+	case string:
+		return parse.Int(rawValue)
+	case int:
+		return rawValue, nil
+	case int64:
+		return int(rawValue), nil
+	case float64:
+		return int(rawValue), nil
+	default:
+		return 0, migi.NewOptionInvalidType(name, value, "int")
+	}
+}
+
+func (s *Source) Float(name string) (float32, error) {
+	value, err := s.Lookup(name)
+	if err != nil {
+		return 0, err
+	}
+
+	switch rawValue := value.(type) {
+	case string:
+		return parse.Float(rawValue)
+	case float32:
+		return rawValue, nil
+	case float64:
+		return float32(rawValue), nil
+	default:
+		return 0, migi.NewOptionInvalidType(name, value, "float")
+	}
+}
+
+func (s *Source) Bool(name string) (bool, error) {
+	value, err := s.Lookup(name)
+	if err != nil {
+		return false, err
+	}
+
+	switch rawValue := value.(type) {
+	case string:
+		return parse.Bool(rawValue)
+	case bool:
+		return rawValue, nil
+	default:
+		return false, migi.NewOptionInvalidType(name, value, "bool")
+	}
+}
+
+func (s *Source) Time(name string) (time.Time, error) {
+	value, err := s.Lookup(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch rawValue := value.(type) {
+	case string:
+		return parse.Time(rawValue)
+	case time.Time:
+		return rawValue, nil
+	default:
+		return time.Time{}, migi.NewOptionInvalidType(name, value, "time.Time")
+	}
+}
+
+func (s *Source) Duration(name string) (time.Duration, error) {
+	value, err := s.Lookup(name)
+	if err != nil {
+		return time.Duration(0), err
+	}
+
+	switch rawValue := value.(type) {
+	case string:
+		return parse.Duration(rawValue)
+	case time.Duration:
+		return rawValue, nil
+	default:
+		return time.Duration(0), migi.NewOptionInvalidType(name, value, "time.Duration")
+	}
+}
+
+// New creates a source serving the provided values
+func New(values map[string]interface{}) *Source {
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+	return &Source{values: values}
+}
@@ -0,0 +1,79 @@
+package mapsource
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	source := New(map[string]interface{}{
+		"string_key":          "string_value",
+		"int_key":             333,
+		"int_string_key":      "550",
+		"float_key":           455.55,
+		"float_string_key":    "555.78",
+		"bool_key":            true,
+		"bool_string_key":     "true",
+		"time_key":            testutils.NewTime(t, time.RFC3339, "2019-05-23T00:00:00Z"),
+		"time_string_key":     "2019-05-23T00:00:00Z",
+		"duration_key":        time.Minute * 5,
+		"duration_string_key": "5m",
+	})
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+
+	for _, key := range []string{"int_key", "int_string_key"} {
+		value, err := source.Int(key)
+		assert.NoError(t, err)
+		assert.NotZero(t, value)
+	}
+	for _, key := range []string{"float_key", "float_string_key"} {
+		value, err := source.Float(key)
+		assert.NoError(t, err)
+		assert.NotZero(t, value)
+	}
+	for _, key := range []string{"bool_key", "bool_string_key"} {
+		value, err := source.Bool(key)
+		assert.NoError(t, err)
+		assert.True(t, value)
+	}
+	for _, key := range []string{"time_key", "time_string_key"} {
+		value, err := source.Time(key)
+		assert.NoError(t, err)
+		assert.Equal(t, testutils.NewTime(t, time.RFC3339, "2019-05-23T00:00:00Z"), value)
+	}
+	for _, key := range []string{"duration_key", "duration_string_key"} {
+		value, err := source.Duration(key)
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute*5, value)
+	}
+
+	_, err = source.String("missing_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+
+	_, err = source.String("int_key")
+	assert.IsType(t, migi.OptionInvalidType{}, err)
+
+	keys := source.Names()
+	assert.Contains(t, keys, "int_key")
+	assert.True(t, sort.StringsAreSorted(keys))
+
+	rawValue, err := source.Value("int_key")
+	assert.NoError(t, err)
+	assert.Equal(t, 333, rawValue)
+
+	source.Set(map[string]interface{}{"string_key": "new_value"})
+	stringValue, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "new_value", stringValue)
+}
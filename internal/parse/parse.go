@@ -0,0 +1,48 @@
+package parse
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Int parses a raw string value as an int option
+func Int(value string) (int, error) {
+	intValue, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(intValue), nil
+}
+
+// Float parses a raw string value as a float option
+func Float(value string) (float32, error) {
+	floatValue, err := strconv.ParseFloat(value, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return float32(floatValue), nil
+}
+
+// Bool parses a raw string value as a bool option
+func Bool(value string) (bool, error) {
+	return strconv.ParseBool(value)
+}
+
+// Time parses a raw RFC3339 string value as a time option
+func Time(value string) (time.Time, error) {
+	return time.Parse(time.RFC3339, value)
+}
+
+// Duration parses a raw string value as a duration option
+func Duration(value string) (time.Duration, error) {
+	return time.ParseDuration(value)
+}
+
+// TrimNewline removes one trailing line ending, \n, \r\n or \r, from a raw
+// value read from a file or a command output
+func TrimNewline(value string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(value, "\n"), "\r")
+}
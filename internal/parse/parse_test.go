@@ -0,0 +1,55 @@
+package parse
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	intValue, err := Int("333")
+	assert.NoError(t, err)
+	assert.Equal(t, 333, intValue)
+
+	floatValue, err := Float("333.33")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(333.33), floatValue)
+
+	boolValue, err := Bool("true")
+	assert.NoError(t, err)
+	assert.Equal(t, true, boolValue)
+
+	timeValue, err := Time("2019-05-23T00:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, testutils.NewTime(t, time.RFC3339, "2019-05-23T00:00:00Z"), timeValue)
+
+	durationValue, err := Duration("5m")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute*5, durationValue)
+}
+
+func TestTrimNewline(t *testing.T) {
+	assert.Equal(t, "value", TrimNewline("value\n"))
+	assert.Equal(t, "value", TrimNewline("value\r\n"))
+	assert.Equal(t, "value\n", TrimNewline("value\n\n"))
+	assert.Equal(t, "value", TrimNewline("value"))
+}
+
+func TestParseError(t *testing.T) {
+	_, err := Int("not_int")
+	assert.Error(t, err)
+
+	_, err = Float("not_float")
+	assert.Error(t, err)
+
+	_, err = Bool("not_bool")
+	assert.Error(t, err)
+
+	_, err = Time("2019-05-23")
+	assert.Error(t, err)
+
+	_, err = Duration("not_duration")
+	assert.Error(t, err)
+}
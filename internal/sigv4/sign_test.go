@@ -0,0 +1,47 @@
+package sigv4
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	// get-vanilla from the aws signature version 4 test suite
+	request, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+
+	Sign(
+		request,
+		nil,
+		Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"},
+		"us-east-1",
+		"service",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC),
+	)
+
+	assert.Equal(t, "20150830T123600Z", request.Header.Get("X-Amz-Date"))
+	assert.Equal(t,
+		"AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, "+
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		request.Header.Get("Authorization"),
+	)
+}
+
+func TestSignSessionToken(t *testing.T) {
+	request, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/?b=2&a=1", nil)
+	require.NoError(t, err)
+	request.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+
+	Sign(request, nil, Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token"}, "us-east-1", "s3", time.Now())
+
+	assert.Equal(t, "token", request.Header.Get("X-Amz-Security-Token"))
+	assert.Contains(t, request.Header.Get("Authorization"),
+		"SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token",
+	)
+	assert.Equal(t, "a=1&b=2", canonicalQuery(request.URL))
+}
@@ -0,0 +1,211 @@
+package migi
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rjansen/abend"
+)
+
+const envPrefix = "env:"
+
+// interpolator expands ${other.key}, ${env:NAME} and ${key:-fallback}
+// expressions found in string options, $${ escapes a literal ${
+type interpolator struct {
+	options  map[string]*option
+	sources  []Source
+	resolved map[string]string
+	visiting []string
+}
+
+// expand interpolates every string option with the values loaded from the layers
+func (o *options) expand(layers []layer) error {
+	var errs []error
+	interpolator := newInterpolator(o.register, layerSources(layers))
+	for _, option := range o.register {
+		pointer, is := option.pointer.(*string)
+		if !is {
+			continue
+		}
+		value, err := interpolator.option(option.name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		*pointer = value
+	}
+
+	if len(errs) > 0 {
+		return abend.NewList(errs...)
+	}
+
+	return nil
+}
+
+func newInterpolator(register []*option, sources []Source) *interpolator {
+	options := make(map[string]*option, len(register))
+	for _, option := range register {
+		options[option.name] = option
+	}
+
+	return &interpolator{
+		options:  options,
+		sources:  sources,
+		resolved: make(map[string]string),
+	}
+}
+
+// lookup returns the final value of a registered option or, for unregistered
+// names, the string value held by the sources
+func (i *interpolator) lookup(name string) (string, bool, error) {
+	if value, ok := i.resolved[name]; ok {
+		return value, true, nil
+	}
+	for index, visiting := range i.visiting {
+		if visiting == name {
+			cycle := append(append([]string{}, i.visiting[index:]...), name)
+			return "", true, fmt.Errorf("cycle %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	var raw string
+	if option, ok := i.options[name]; ok {
+		pointer, is := option.pointer.(*string)
+		if !is {
+			return formatPointer(option.pointer), true, nil
+		}
+		raw = *pointer
+	} else {
+		found := false
+		for index := len(i.sources) - 1; index >= 0; index-- {
+			value, err := i.sources[index].String(name)
+			if err == nil {
+				raw, found = value, true
+				break
+			}
+		}
+		if !found {
+			return "", false, nil
+		}
+	}
+
+	i.visiting = append(i.visiting, name)
+	value, err := i.expand(raw)
+	i.visiting = i.visiting[:len(i.visiting)-1]
+	if err != nil {
+		return "", true, err
+	}
+	i.resolved[name] = value
+
+	return value, true, nil
+}
+
+// option interpolates the value of a registered string option
+func (i *interpolator) option(name string) (string, error) {
+	value, _, err := i.lookup(name)
+	if err != nil {
+		return "", NewOptionNotInterpolated(name, err)
+	}
+
+	return value, nil
+}
+
+func (i *interpolator) expand(value string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var result strings.Builder
+	for position := 0; position < len(value); {
+		switch {
+		case strings.HasPrefix(value[position:], "$${"):
+			result.WriteString("${")
+			position += 3
+		case strings.HasPrefix(value[position:], "${"):
+			end := closingBrace(value, position+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated expression %s", value[position:])
+			}
+			expanded, err := i.expression(value[position+2 : end])
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(expanded)
+			position = end + 1
+		default:
+			result.WriteByte(value[position])
+			position++
+		}
+	}
+
+	return result.String(), nil
+}
+
+func (i *interpolator) expression(expression string) (string, error) {
+	name, fallback, hasFallback := expression, "", false
+	if index := strings.Index(expression, ":-"); index >= 0 {
+		name, fallback, hasFallback = expression[:index], expression[index+2:], true
+	}
+
+	var (
+		value string
+		found bool
+		err   error
+	)
+	if strings.HasPrefix(name, envPrefix) {
+		value, found = os.LookupEnv(strings.TrimPrefix(name, envPrefix))
+	} else {
+		value, found, err = i.lookup(name)
+		if err != nil {
+			return "", err
+		}
+	}
+	if found {
+		return value, nil
+	}
+	if hasFallback {
+		return i.expand(fallback)
+	}
+
+	return "", fmt.Errorf("%s not found", name)
+}
+
+// closingBrace returns the index of the brace closing the expression starting at start
+func closingBrace(value string, start int) int {
+	depth := 1
+	for index := start; index < len(value); index++ {
+		switch {
+		case strings.HasPrefix(value[index:], "${"):
+			depth++
+			index++
+		case value[index] == '}':
+			depth--
+			if depth == 0 {
+				return index
+			}
+		}
+	}
+
+	return -1
+}
+
+// formatPointer returns the text of the value pointer points to
+func formatPointer(pointer interface{}) string {
+	switch value := pointer.(type) {
+	case *int:
+		return strconv.Itoa(*value)
+	case *float32:
+		return strconv.FormatFloat(float64(*value), 'g', -1, 32)
+	case *bool:
+		return strconv.FormatBool(*value)
+	case *time.Time:
+		return value.Format(time.RFC3339)
+	case *time.Duration:
+		return value.String()
+	default:
+		return fmt.Sprint(pointer)
+	}
+}
@@ -0,0 +1,101 @@
+package migi
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolation(t *testing.T) {
+	os.Setenv("MIGI_INTERPOLATION_HOME", "/home/migi")
+	defer os.Unsetenv("MIGI_INTERPOLATION_HOME")
+
+	scenarios := []struct {
+		name      string
+		sources   map[string]interface{}
+		expected  map[string]string
+		loadError string
+	}{
+		{
+			name: "when values reference options, sources and environment",
+			sources: map[string]interface{}{
+				"db.host":     "db.local",
+				"db.port":     5432,
+				"db.url":      "postgres://${db.host}:${db.port}/${db.name:-app}",
+				"cache.dir":   "${env:MIGI_INTERPOLATION_HOME}/cache",
+				"log.dir":     "${env:MIGI_INTERPOLATION_MISSING:-${cache.dir}/../log}",
+				"unregister":  "${db.host}",
+				"template":    "literal $${db.host} and ${unregister}",
+				"db.timeout":  time.Second * 5,
+				"description": "timeout ${db.timeout}",
+			},
+			expected: map[string]string{
+				"db.url":      "postgres://db.local:5432/app",
+				"cache.dir":   "/home/migi/cache",
+				"log.dir":     "/home/migi/cache/../log",
+				"template":    "literal ${db.host} and db.local",
+				"description": "timeout 5s",
+			},
+		},
+		{
+			name: "when values reference each other",
+			sources: map[string]interface{}{
+				"db.url":    "${db.host}",
+				"db.host":   "${db.url}",
+				"cache.dir": "${missing.key}",
+				"log.dir":   "${cache.dir",
+			},
+			loadError: "errors.List{" +
+				"errors.OptionNotInterpolated{Name='db.url', Cause='cycle db.url -> db.host -> db.url'}, " +
+				"errors.OptionNotInterpolated{Name='cache.dir', Cause='missing.key not found'}, " +
+				"errors.OptionNotInterpolated{Name='log.dir', Cause='unterminated expression ${cache.dir'}, " +
+				"errors.OptionNotInterpolated{Name='db.host', Cause='cycle db.host -> db.url -> db.host'}}",
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				options := NewOptions(&mockSource{options: scenario.sources})
+				options.(InterpolatingOptions).Interpolate(true)
+				values := map[string]*string{
+					"db.url":      options.String("db.url", "", "the database url"),
+					"cache.dir":   options.String("cache.dir", "", "the cache directory"),
+					"log.dir":     options.String("log.dir", "", "the log directory"),
+					"template":    options.String("template", "", "a template"),
+					"description": options.String("description", "", "a description"),
+					"db.host":     options.String("db.host", "", "the database host"),
+				}
+				options.Int("db.port", 0, "the database port")
+				options.Duration("db.timeout", 0, "the database timeout")
+
+				err := options.Load()
+				if scenario.loadError != "" {
+					require.EqualError(t, err, scenario.loadError)
+					return
+				}
+				require.NoError(t, err)
+				for name, value := range scenario.expected {
+					assert.Equal(t, value, *values[name], name)
+				}
+			},
+		)
+	}
+}
+
+func TestInterpolationDisabled(t *testing.T) {
+	options := NewOptions(&mockSource{options: map[string]interface{}{
+		"db.host": "db.local",
+		"db.url":  "postgres://${db.host}/$${app}",
+	}})
+	url := options.String("db.url", "", "the database url")
+	options.String("db.host", "", "the database host")
+
+	require.NoError(t, options.Load())
+	assert.Equal(t, "postgres://${db.host}/$${app}", *url)
+}
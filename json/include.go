@@ -0,0 +1,102 @@
+package environment
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// includeKey lists the files merged under the document holding it,
+	// paths are relative to the including file
+	includeKey = "$include"
+	// maxIncludeDepth limits how deep includes may nest
+	maxIncludeDepth = 8
+)
+
+func decodeFile(path string, stack []string) (map[string]interface{}, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, including := range stack {
+		if including == absolute {
+			return nil, fmt.Errorf("json: include cycle %s -> %s", strings.Join(stack, " -> "), absolute)
+		}
+	}
+	if len(stack) > maxIncludeDepth {
+		return nil, fmt.Errorf("json: include depth exceeds %d at %s", maxIncludeDepth, absolute)
+	}
+
+	file, err := os.Open(absolute)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return decode(file, absolute, append(stack, absolute))
+}
+
+// decode reads a document merging its includes first, in order, and then its
+// own options over them. Objects are merged key by key, any other value replaces the included one
+func decode(reader io.Reader, path string, stack []string) (map[string]interface{}, error) {
+	document := make(map[string]interface{})
+	if err := json.NewDecoder(reader).Decode(&document); err != nil {
+		return nil, err
+	}
+	include, ok := document[includeKey]
+	if !ok {
+		return document, nil
+	}
+	delete(document, includeKey)
+
+	var includes []string
+	switch value := include.(type) {
+	case string:
+		includes = []string{value}
+	case []interface{}:
+		for _, item := range value {
+			itemPath, is := item.(string)
+			if !is {
+				return nil, fmt.Errorf("json: %s must list file paths", includeKey)
+			}
+			includes = append(includes, itemPath)
+		}
+	default:
+		return nil, fmt.Errorf("json: %s must list file paths", includeKey)
+	}
+
+	base := filepath.Dir(path)
+	if path == "" {
+		base = "."
+	}
+	options := make(map[string]interface{})
+	for _, included := range includes {
+		if !filepath.IsAbs(included) {
+			included = filepath.Join(base, included)
+		}
+		includedOptions, err := decodeFile(included, stack)
+		if err != nil {
+			return nil, err
+		}
+		merge(options, includedOptions)
+	}
+	merge(options, document)
+
+	return options, nil
+}
+
+func merge(target map[string]interface{}, source map[string]interface{}) {
+	for key, value := range source {
+		sourceMap, isMap := value.(map[string]interface{})
+		targetMap, targetIsMap := target[key].(map[string]interface{})
+		if isMap && targetIsMap {
+			merge(targetMap, sourceMap)
+			continue
+		}
+		target[key] = value
+	}
+}
@@ -0,0 +1,86 @@
+package environment
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	path, err := ioutil.TempDir("", "migi-json-include")
+	require.NoError(t, err)
+	for name, content := range files {
+		file := filepath.Join(path, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+	return path
+}
+
+func TestInclude(t *testing.T) {
+	path := writeFiles(t, map[string]string{
+		"app/config.json": `{
+			"$include": ["../common/logging.json", "../common/tracing.json"],
+			"log_level": "warn",
+			"tracing": {"sample_rate": 0.5}
+		}`,
+		"common/logging.json":  `{"$include": "defaults.json", "log_level": "info", "log_format": "json"}`,
+		"common/defaults.json": `{"log_level": "debug", "log_output": "stdout"}`,
+		"common/tracing.json":  `{"tracing": {"endpoint": "tracing.local:4317", "sample_rate": 1}}`,
+	})
+	defer os.RemoveAll(path)
+
+	source := NewFileSource(filepath.Join(path, "app", "config.json"))
+	require.NoError(t, source.Load())
+
+	for key, expected := range map[string]string{
+		"log_level":  "warn",
+		"log_format": "json",
+		"log_output": "stdout",
+	} {
+		value, err := source.String(key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, value, key)
+	}
+	assert.Equal(t,
+		map[string]interface{}{"endpoint": "tracing.local:4317", "sample_rate": 0.5},
+		source.options["tracing"],
+	)
+	_, ok := source.options[includeKey]
+	assert.False(t, ok)
+}
+
+func TestIncludeError(t *testing.T) {
+	files := map[string]string{
+		"cycle/a.json":   `{"$include": "b.json"}`,
+		"cycle/b.json":   `{"$include": ["a.json"]}`,
+		"invalid.json":   `{"$include": 1}`,
+		"missing.json":   `{"$include": "other/missing.json"}`,
+		"depth/0.json":   `{}`,
+		"depth/top.json": fmt.Sprintf(`{"$include": "%d.json"}`, maxIncludeDepth+1),
+	}
+	for depth := 1; depth <= maxIncludeDepth+1; depth++ {
+		files[fmt.Sprintf("depth/%d.json", depth)] = fmt.Sprintf(`{"$include": "%d.json"}`, depth-1)
+	}
+	path := writeFiles(t, files)
+	defer os.RemoveAll(path)
+
+	err := NewFileSource(filepath.Join(path, "cycle", "a.json")).Load()
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "json: include cycle"), err.Error())
+	assert.True(t, strings.HasSuffix(err.Error(), filepath.Join("cycle", "a.json")), err.Error())
+
+	err = NewFileSource(filepath.Join(path, "depth", "top.json")).Load()
+	require.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "json: include depth exceeds 8"), err.Error())
+
+	assert.NoError(t, NewFileSource(filepath.Join(path, "depth", fmt.Sprintf("%d.json", maxIncludeDepth))).Load())
+	assert.EqualError(t, NewFileSource(filepath.Join(path, "invalid.json")).Load(), "json: $include must list file paths")
+	assert.Error(t, NewFileSource(filepath.Join(path, "missing.json")).Load())
+}
@@ -0,0 +1,27 @@
+package environment
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("file", open)
+}
+
+// open creates the source of file:///etc/app/config.json or, relative to
+// the working directory, file:config.json
+func open(u *url.URL) (migi.Source, error) {
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+	if strings.ToLower(filepath.Ext(path)) != ".json" {
+		return nil, migi.NewFormatNotSupported(path)
+	}
+
+	return NewFileSource(filepath.FromSlash(path)), nil
+}
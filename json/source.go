@@ -1,21 +1,64 @@
 package environment
 
 import (
-	"encoding/json"
 	"io"
-	"strconv"
+	"os"
+	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/parse"
 )
 
 type source struct {
 	reader  io.Reader
+	path    string
 	options map[string]interface{}
 }
 
 func (e *source) Load() error {
-	return json.NewDecoder(e.reader).Decode(&e.options)
+	var (
+		options map[string]interface{}
+		err     error
+	)
+	if e.path == "" {
+		options, err = decode(e.reader, "", nil)
+	} else {
+		options, err = decodeFile(e.path, nil)
+	}
+	if err != nil {
+		return err
+	}
+	e.options = options
+
+	return nil
+}
+
+// Profile returns the file source of the profile overlay, a sibling file
+// named after the profile, e.g. prod.json next to base.json
+func (e *source) Profile(name string) (migi.Source, error) {
+	if e.path == "" {
+		return nil, nil
+	}
+
+	path := filepath.Join(filepath.Dir(e.path), name+filepath.Ext(e.path))
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return NewFileSource(path), nil
+}
+
+func (e *source) Provenance() string {
+	if e.path == "" {
+		return "json"
+	}
+
+	return "file:" + e.path
 }
 
 func (e *source) lookup(name string) (interface{}, error) {
@@ -27,6 +70,22 @@ func (e *source) lookup(name string) (interface{}, error) {
 	return value, nil
 }
 
+// Keys lists the names of the top level values, see migi.KeyLister
+func (e *source) Keys() []string {
+	keys := make([]string, 0, len(e.options))
+	for key := range e.options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Value returns the raw value of name, nested objects are maps and arrays are slices
+func (e *source) Value(name string) (interface{}, error) {
+	return e.lookup(name)
+}
+
 func (e *source) String(name string) (string, error) {
 	value, err := e.lookup(name)
 	if err != nil {
@@ -49,11 +108,7 @@ func (e *source) Int(name string) (int, error) {
 
 	switch rawValue := value.(type) {
 	case string:
-		intValue, err := strconv.ParseInt(rawValue, 10, 32)
-		if err != nil {
-			return 0, err
-		}
-		return int(intValue), nil
+		return parse.Int(rawValue)
 	case float64:
 		return int(rawValue), nil
 	default:
@@ -69,11 +124,7 @@ func (e *source) Float(name string) (float32, error) {
 
 	switch rawValue := value.(type) {
 	case string:
-		floatValue, err := strconv.ParseFloat(rawValue, 32)
-		if err != nil {
-			return 0, err
-		}
-		return float32(floatValue), nil
+		return parse.Float(rawValue)
 	case float64:
 		return float32(rawValue), nil
 	default:
@@ -89,11 +140,7 @@ func (e *source) Bool(name string) (bool, error) {
 
 	switch rawValue := value.(type) {
 	case string:
-		boolValue, err := strconv.ParseBool(rawValue)
-		if err != nil {
-			return false, err
-		}
-		return boolValue, nil
+		return parse.Bool(rawValue)
 	case bool:
 		return rawValue, nil
 	default:
@@ -109,11 +156,7 @@ func (e *source) Time(name string) (time.Time, error) {
 
 	switch rawValue := value.(type) {
 	case string:
-		timeValue, err := time.Parse(time.RFC3339, rawValue)
-		if err != nil {
-			return time.Time{}, err
-		}
-		return timeValue, nil
+		return parse.Time(rawValue)
 	case time.Time:
 		return rawValue, nil
 	default:
@@ -129,11 +172,7 @@ func (e *source) Duration(name string) (time.Duration, error) {
 
 	switch rawValue := value.(type) {
 	case string:
-		durationValue, err := time.ParseDuration(rawValue)
-		if err != nil {
-			return time.Duration(0), err
-		}
-		return durationValue, nil
+		return parse.Duration(rawValue)
 	case time.Duration:
 		return rawValue, nil
 	default:
@@ -147,3 +186,11 @@ func NewSource(reader io.Reader) *source {
 		options: make(map[string]interface{}),
 	}
 }
+
+// NewFileSource creates a json source that reads the file at path on every Load
+func NewFileSource(path string) *source {
+	return &source{
+		path:    path,
+		options: make(map[string]interface{}),
+	}
+}
@@ -3,6 +3,9 @@ package environment
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -114,3 +117,80 @@ func TestEnvironmentSource(t *testing.T) {
 		)
 	}
 }
+
+func TestFileSource(t *testing.T) {
+	path, err := ioutil.TempDir("", "migi-json")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(path, "base.json"), []byte(`{"string_key": "base_value"}`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(path, "prod.json"), []byte(`{"string_key": "prod_value"}`), 0644))
+
+	source := NewFileSource(filepath.Join(path, "base.json"))
+	require.Implements(t, (*migi.ProfileSource)(nil), source)
+	require.Implements(t, (*migi.Provenancer)(nil), source)
+	require.NoError(t, source.Load())
+	assert.Equal(t, "file:"+filepath.Join(path, "base.json"), source.Provenance())
+
+	value, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "base_value", value)
+
+	overlay, err := source.Profile("prod")
+	require.NoError(t, err)
+	require.NotNil(t, overlay)
+	require.NoError(t, overlay.Load())
+	value, err = overlay.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod_value", value)
+
+	overlay, err = source.Profile("staging")
+	assert.NoError(t, err)
+	assert.Nil(t, overlay)
+
+	overlay, err = NewSource(bytes.NewReader(nil)).Profile("prod")
+	assert.NoError(t, err)
+	assert.Nil(t, overlay)
+
+	assert.Error(t, NewFileSource(filepath.Join(path, "missing.json")).Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("file:///etc/app/config.json")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.FromSlash("/etc/app/config.json"), opened.(*source).path)
+
+	opened, err = migi.OpenSource("file:config.json")
+	require.NoError(t, err)
+	assert.Equal(t, "config.json", opened.(*source).path)
+
+	_, err = migi.OpenSource("file:///etc/app/config.toml")
+	assert.Equal(t, migi.NewFormatNotSupported("/etc/app/config.toml"), err)
+}
+
+func TestSourceValues(t *testing.T) {
+	source := NewSource(bytes.NewBufferString(`{
+		"db": {"host": "db.local", "port": "5432", "pool": {"size": 10}},
+		"db.user": "flat_user",
+		"servers": ["a.local", "b.local"]
+	}`))
+	require.NoError(t, source.Load())
+
+	user, err := source.String("db.user")
+	assert.NoError(t, err)
+	assert.Equal(t, "flat_user", user)
+	_, err = source.String("db.host")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+
+	servers, err := source.Value("servers")
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"a.local", "b.local"}, servers)
+	db, err := source.Value("db")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"host": "db.local", "port": "5432", "pool": map[string]interface{}{"size": float64(10)},
+	}, db)
+	_, err = source.Value("db.host")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+
+	assert.Equal(t, []string{"db", "db.user", "servers"}, source.Keys())
+}
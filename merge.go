@@ -0,0 +1,262 @@
+package migi
+
+import (
+	"strings"
+	"time"
+
+	"github.com/rjansen/abend"
+)
+
+const (
+	// LastWins makes the sources merged later take precedence, like NewOptions does
+	LastWins Precedence = iota
+	// FirstWins makes the sources merged first take precedence
+	FirstWins
+)
+
+const (
+	// ReplaceSlices makes a slice replace the slices of lower precedence sources
+	ReplaceSlices SliceMerge = iota
+	// AppendSlices appends a slice to the slices of lower precedence sources
+	AppendSlices
+)
+
+type (
+	// Precedence defines which of the merged sources supplies a value found in many of them
+	Precedence int
+
+	// SliceMerge defines how slices found in many merged sources are combined
+	SliceMerge int
+
+	// Merger combines sources into one, its zero value merges like NewOptions
+	Merger struct {
+		Precedence Precedence
+		Slices     SliceMerge
+	}
+
+	// mergeSource serves the values of many sources as one
+	mergeSource struct {
+		merger  Merger
+		sources []Source
+	}
+)
+
+// Merge combines sources into a single one, the sources merged later take precedence
+func Merge(sources ...Source) Source {
+	return Merger{}.Merge(sources...)
+}
+
+// Merge combines sources into a single one. Scalar values come from the
+// highest precedence source holding them, raw values read through Value are
+// deep merged: maps key by key and slices as configured by the merger
+func (m Merger) Merge(sources ...Source) Source {
+	return &mergeSource{merger: m, sources: sources}
+}
+
+// ordered returns the sources from the lowest to the highest precedence
+func (m *mergeSource) ordered() []Source {
+	if m.merger.Precedence == LastWins {
+		return m.sources
+	}
+
+	ordered := make([]Source, len(m.sources))
+	for index, source := range m.sources {
+		ordered[len(m.sources)-1-index] = source
+	}
+
+	return ordered
+}
+
+func (m *mergeSource) Load() error {
+	var errs []error
+	for _, source := range m.sources {
+		if err := source.Load(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return abend.NewList(errs...)
+	}
+
+	return nil
+}
+
+func (m *mergeSource) Provenance() string {
+	provenances := make([]string, len(m.sources))
+	for index, source := range m.ordered() {
+		provenances[index] = provenance(source)
+	}
+
+	return "merge(" + strings.Join(provenances, ", ") + ")"
+}
+
+// Profile merges the profile overlays of the merged sources the same way,
+// they take precedence over every merged source as a whole
+func (m *mergeSource) Profile(name string) (Source, error) {
+	var overlays []Source
+	for _, source := range m.sources {
+		profileSource, is := source.(ProfileSource)
+		if !is {
+			continue
+		}
+		overlay, err := profileSource.Profile(name)
+		if err != nil {
+			return nil, err
+		}
+		if overlay != nil {
+			overlays = append(overlays, overlay)
+		}
+	}
+	if len(overlays) == 0 {
+		return nil, nil
+	}
+
+	return m.merger.Merge(overlays...), nil
+}
+
+// Keys lists the option names of every merged source implementing KeyLister
+func (m *mergeSource) Keys() []string {
+	return sortedKeys(m.sources...)
+}
+
+// Value returns the raw value of the option deep merged from every source holding it.
+// Sources not implementing Valuer contribute their string values
+func (m *mergeSource) Value(name string) (interface{}, error) {
+	var (
+		merged interface{}
+		found  bool
+	)
+	for _, source := range m.ordered() {
+		value, err := rawValue(source, name)
+		if err != nil {
+			if _, is := err.(OptionNotFound); is {
+				continue
+			}
+			return nil, err
+		}
+		if found {
+			value = m.merger.merge(merged, value)
+		}
+		merged, found = value, true
+	}
+	if !found {
+		return nil, NewOptionNotFound(name)
+	}
+
+	return merged, nil
+}
+
+// rawValue reads the raw value of name, or the string value when the source is not a Valuer
+func rawValue(source Source, name string) (interface{}, error) {
+	if valuer, is := source.(Valuer); is {
+		return valuer.Value(name)
+	}
+
+	return source.String(name)
+}
+
+// merge combines the base value with the value overriding it
+func (m Merger) merge(base interface{}, value interface{}) interface{} {
+	switch overriding := value.(type) {
+	case map[string]interface{}:
+		baseMap, is := base.(map[string]interface{})
+		if !is {
+			return value
+		}
+		merged := make(map[string]interface{}, len(baseMap)+len(overriding))
+		for key, baseValue := range baseMap {
+			merged[key] = baseValue
+		}
+		for key, overridingValue := range overriding {
+			if baseValue, ok := merged[key]; ok {
+				overridingValue = m.merge(baseValue, overridingValue)
+			}
+			merged[key] = overridingValue
+		}
+		return merged
+	case []interface{}:
+		baseSlice, is := base.([]interface{})
+		if !is || m.Slices == ReplaceSlices {
+			return value
+		}
+		merged := make([]interface{}, 0, len(baseSlice)+len(overriding))
+		return append(append(merged, baseSlice...), overriding...)
+	default:
+		return value
+	}
+}
+
+// read calls get with the sources from the highest to the lowest precedence
+// until one of them holds the option
+func (m *mergeSource) read(name string, get func(Source) error) error {
+	ordered := m.ordered()
+	for index := len(ordered) - 1; index >= 0; index-- {
+		err := get(ordered[index])
+		if _, is := err.(OptionNotFound); is {
+			continue
+		}
+		return err
+	}
+
+	return NewOptionNotFound(name)
+}
+
+func (m *mergeSource) String(name string) (string, error) {
+	var value string
+	err := m.read(name, func(source Source) (err error) {
+		value, err = source.String(name)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mergeSource) Int(name string) (int, error) {
+	var value int
+	err := m.read(name, func(source Source) (err error) {
+		value, err = source.Int(name)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mergeSource) Float(name string) (float32, error) {
+	var value float32
+	err := m.read(name, func(source Source) (err error) {
+		value, err = source.Float(name)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mergeSource) Bool(name string) (bool, error) {
+	var value bool
+	err := m.read(name, func(source Source) (err error) {
+		value, err = source.Bool(name)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mergeSource) Time(name string) (time.Time, error) {
+	var value time.Time
+	err := m.read(name, func(source Source) (err error) {
+		value, err = source.Time(name)
+		return err
+	})
+
+	return value, err
+}
+
+func (m *mergeSource) Duration(name string) (time.Duration, error) {
+	var value time.Duration
+	err := m.read(name, func(source Source) (err error) {
+		value, err = source.Duration(name)
+		return err
+	})
+
+	return value, err
+}
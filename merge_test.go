@@ -0,0 +1,177 @@
+package migi
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rjansen/abend"
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// plainSource hides the optional interfaces of the wrapped source
+	plainSource struct {
+		Source
+	}
+
+	testMerge struct {
+		name     string
+		merger   Merger
+		expected map[string]interface{}
+	}
+)
+
+func TestMerge(t *testing.T) {
+	defaults := &mockSource{options: map[string]interface{}{
+		"string_key":   "default_value",
+		"int_key":      1,
+		"duration_key": time.Second,
+		"db": map[string]interface{}{
+			"host": "default.local",
+			"pool": map[string]interface{}{"size": 5, "timeout": "1s"},
+		},
+		"servers": []interface{}{"default.local"},
+	}}
+	embedded := &mockSource{options: map[string]interface{}{
+		"string_key": "embedded_value",
+		"db": map[string]interface{}{
+			"pool": map[string]interface{}{"size": 10},
+		},
+		"servers": []interface{}{"embedded.local"},
+	}}
+	env := plainSource{&mockSource{options: map[string]interface{}{"MERGE_KEY": "env_value"}}}
+
+	scenarios := []testMerge{
+		{
+			name: "later sources take precedence and replace slices",
+			expected: map[string]interface{}{
+				"string_key":   "embedded_value",
+				"int_key":      1,
+				"duration_key": time.Second,
+				"MERGE_KEY":    "env_value",
+				"db": map[string]interface{}{
+					"host": "default.local",
+					"pool": map[string]interface{}{"size": 10, "timeout": "1s"},
+				},
+				"servers": []interface{}{"embedded.local"},
+			},
+		},
+		{
+			name:   "first sources take precedence and append slices",
+			merger: Merger{Precedence: FirstWins, Slices: AppendSlices},
+			expected: map[string]interface{}{
+				"string_key": "default_value",
+				"db": map[string]interface{}{
+					"host": "default.local",
+					"pool": map[string]interface{}{"size": 5, "timeout": "1s"},
+				},
+				"servers": []interface{}{"embedded.local", "default.local"},
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				source := scenario.merger.Merge(defaults, embedded, env)
+				require.NoError(t, source.Load())
+
+				for key, value := range scenario.expected {
+					switch value.(type) {
+					case string:
+						v, err := source.String(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v)
+					case int:
+						v, err := source.Int(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v)
+					case time.Duration:
+						v, err := source.Duration(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v)
+					default:
+						v, err := source.(Valuer).Value(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v)
+					}
+				}
+
+				_, err := source.String("missing_key")
+				assert.Equal(t, NewOptionNotFound("missing_key"), err)
+				_, err = source.(Valuer).Value("missing_key")
+				assert.Equal(t, NewOptionNotFound("missing_key"), err)
+				_, err = source.Bool("string_key")
+				assert.IsType(t, OptionInvalidType{}, err)
+			},
+		)
+	}
+}
+
+func TestMergeOptions(t *testing.T) {
+	options := NewOptions(
+		Merge(
+			&provenanceMockSource{
+				mockSource: mockSource{options: map[string]interface{}{"string_key": "defaults_value", "int_key": 1}},
+				provenance: "defaults",
+			},
+			&mockSource{options: map[string]interface{}{"string_key": "overriding_value"}},
+		),
+	)
+	stringValue := options.String("string_key", "", "")
+	intValue := options.Int("int_key", 0, "")
+	require.NoError(t, options.Load())
+
+	assert.Equal(t, "overriding_value", *stringValue)
+	assert.Equal(t, 1, *intValue)
+	assert.Equal(t, "merge(defaults, *migi.mockSource)", options.(ProvenanceOptions).Provenance("string_key"))
+}
+
+func TestMergeProfile(t *testing.T) {
+	options := NewOptions(
+		Merge(
+			&profileSource{
+				Source: &mockSource{options: map[string]interface{}{"app.profile": "prod", "string_key": "base_value"}},
+				profiles: map[string]Source{
+					"prod": &provenanceMockSource{
+						mockSource: mockSource{options: map[string]interface{}{"string_key": "prod_value"}},
+						provenance: "prod",
+					},
+				},
+			},
+			&profileSource{
+				Source:   &mockSource{options: map[string]interface{}{"int_key": 1}},
+				profiles: map[string]Source{},
+			},
+			&mockSource{options: map[string]interface{}{"string_key": "overriding_value"}},
+		),
+	)
+	options.(ProfileOptions).Profile("app.profile", "", "")
+	stringValue := options.String("string_key", "", "")
+	intValue := options.Int("int_key", 0, "")
+	require.NoError(t, options.Load())
+
+	assert.Equal(t, "prod_value", *stringValue)
+	assert.Equal(t, 1, *intValue)
+	assert.Equal(t, "merge(prod) (profile prod)", options.(ProvenanceOptions).Provenance("string_key"))
+
+	overlay, err := Merge(&mockSource{}).(ProfileSource).Profile("prod")
+	assert.NoError(t, err)
+	assert.Nil(t, overlay)
+}
+
+func TestMergeLoadError(t *testing.T) {
+	source := Merge(
+		&mockSource{loadError: errors.New("mock_error_1")},
+		&mockSource{},
+		&mockSource{loadError: errors.New("mock_error_2")},
+	)
+	assert.Equal(t,
+		abend.NewList(errors.New("mock_error_1"), errors.New("mock_error_2")),
+		source.Load(),
+	)
+}
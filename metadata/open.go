@@ -0,0 +1,16 @@
+package metadata
+
+import (
+	"net/url"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("metadata", open)
+}
+
+// open creates the source of metadata://
+func open(u *url.URL) (migi.Source, error) {
+	return NewSource(), nil
+}
@@ -0,0 +1,177 @@
+package metadata
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi/httpsource"
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+// Well-known option names served by the metadata source
+const (
+	Provider   = "cloud.provider"
+	Region     = "cloud.region"
+	Zone       = "cloud.zone"
+	InstanceID = "cloud.instance.id"
+)
+
+const (
+	ec2Endpoint = "http://169.254.169.254"
+	gceEndpoint = "http://metadata.google.internal"
+	tokenTTL    = "21600"
+)
+
+// errUnavailable tells a metadata service did not answer its probe request
+var errUnavailable = errors.New("metadata: service unavailable")
+
+type (
+	// Option customizes the metadata source
+	Option func(*source)
+
+	source struct {
+		*mapsource.Source
+		ec2Endpoint string
+		gceEndpoint string
+		client      *http.Client
+	}
+)
+
+// WithEC2Endpoint replaces the ec2 instance metadata service address
+func WithEC2Endpoint(endpoint string) Option {
+	return func(s *source) {
+		s.ec2Endpoint = endpoint
+	}
+}
+
+// WithGCEEndpoint replaces the gce metadata server address
+func WithGCEEndpoint(endpoint string) Option {
+	return func(s *source) {
+		s.gceEndpoint = endpoint
+	}
+}
+
+// WithTimeout limits each metadata request, default 1s
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *source) {
+		s.client = &http.Client{Timeout: timeout}
+	}
+}
+
+// Load probes the ec2 and then the gce metadata services. When none answers,
+// as outside of a cloud instance, no option is served and Load succeeds
+func (s *source) Load() error {
+	for _, load := range []func() (map[string]interface{}, error){s.loadEC2, s.loadGCE} {
+		values, err := load()
+		if err == errUnavailable {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		s.Set(values)
+		return nil
+	}
+	s.Set(make(map[string]interface{}))
+
+	return nil
+}
+
+func (s *source) loadEC2() (map[string]interface{}, error) {
+	request, err := http.NewRequest(http.MethodPut, s.ec2Endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", tokenTTL)
+	token, err := s.do(request)
+	if err != nil {
+		return nil, errUnavailable
+	}
+
+	values := map[string]interface{}{Provider: "aws"}
+	for name, item := range map[string]string{
+		Region:     "placement/region",
+		Zone:       "placement/availability-zone",
+		InstanceID: "instance-id",
+	} {
+		request, err := http.NewRequest(http.MethodGet, s.ec2Endpoint+"/latest/meta-data/"+item, nil)
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Set("X-aws-ec2-metadata-token", token)
+		if values[name], err = s.do(request); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func (s *source) loadGCE() (map[string]interface{}, error) {
+	get := func(item string) (string, error) {
+		request, err := http.NewRequest(http.MethodGet, s.gceEndpoint+"/computeMetadata/v1/"+item, nil)
+		if err != nil {
+			return "", err
+		}
+		request.Header.Set("Metadata-Flavor", "Google")
+		return s.do(request)
+	}
+
+	zone, err := get("instance/zone")
+	if err != nil {
+		return nil, errUnavailable
+	}
+	instanceID, err := get("instance/id")
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]interface{}{Provider: "gcp", InstanceID: instanceID}
+
+	// the zone is answered as projects/<number>/zones/<region>-<zone letter>
+	zone = path.Base(zone)
+	values[Zone] = zone
+	if index := strings.LastIndex(zone, "-"); index > 0 {
+		values[Region] = zone[:index]
+	}
+
+	return values, nil
+}
+
+func (s *source) do(request *http.Request) (string, error) {
+	response, err := s.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", httpsource.NewStatusError(request.URL.String(), response.StatusCode)
+	}
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(body)), nil
+}
+
+// NewSource creates a source serving the cloud provider, region, zone and
+// instance id of the ec2 or gce instance the process runs on. It lists no
+// keys, see migi.KeyLister, so strict options do not report its values
+func NewSource(options ...Option) *source {
+	s := &source{
+		Source:      mapsource.New(nil),
+		ec2Endpoint: ec2Endpoint,
+		gceEndpoint: gceEndpoint,
+		client:      &http.Client{Timeout: time.Second},
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
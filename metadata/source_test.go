@@ -0,0 +1,132 @@
+package metadata
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/httpsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ec2Handler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("my_token"))
+	})
+	for item, value := range map[string]string{
+		"/latest/meta-data/placement/region":            "eu-west-1",
+		"/latest/meta-data/placement/availability-zone": "eu-west-1b",
+		"/latest/meta-data/instance-id":                 "i-0123456789",
+	} {
+		value := value
+		mux.HandleFunc(item, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-aws-ec2-metadata-token") != "my_token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(value))
+		})
+	}
+	return mux
+}
+
+func gceHandler(t *testing.T) http.Handler {
+	mux := http.NewServeMux()
+	for item, value := range map[string]string{
+		"/computeMetadata/v1/instance/zone": "projects/123456/zones/us-central1-a",
+		"/computeMetadata/v1/instance/id":   "987654321\n",
+	} {
+		value := value
+		mux.HandleFunc(item, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			w.Write([]byte(value))
+		})
+	}
+	return mux
+}
+
+func assertOptions(t *testing.T, source *source, expected map[string]string) {
+	for name, value := range expected {
+		v, err := source.String(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, value, v, name)
+	}
+}
+
+func TestSourceEC2(t *testing.T) {
+	ec2 := httptest.NewServer(ec2Handler(t))
+	defer ec2.Close()
+
+	source := NewSource(WithEC2Endpoint(ec2.URL), WithGCEEndpoint("http://127.0.0.1:0"))
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	assertOptions(t, source, map[string]string{
+		Provider:   "aws",
+		Region:     "eu-west-1",
+		Zone:       "eu-west-1b",
+		InstanceID: "i-0123456789",
+	})
+}
+
+func TestSourceGCE(t *testing.T) {
+	// the gce metadata server also listens on the ec2 address but rejects its token request
+	gce := httptest.NewServer(gceHandler(t))
+	defer gce.Close()
+
+	source := NewSource(WithEC2Endpoint(gce.URL), WithGCEEndpoint(gce.URL))
+	require.NoError(t, source.Load())
+
+	assertOptions(t, source, map[string]string{
+		Provider:   "gcp",
+		Region:     "us-central1",
+		Zone:       "us-central1-a",
+		InstanceID: "987654321",
+	})
+}
+
+func TestSourceUnavailable(t *testing.T) {
+	blackhole := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond * 200)
+	}))
+	defer blackhole.Close()
+
+	source := NewSource(WithEC2Endpoint(blackhole.URL), WithGCEEndpoint(blackhole.URL), WithTimeout(time.Millisecond*20))
+	require.NoError(t, source.Load())
+
+	_, err := source.String(Region)
+	assert.IsType(t, migi.OptionNotFound{}, err)
+}
+
+func TestSourceError(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("my_token"))
+	})
+	ec2 := httptest.NewServer(mux)
+	defer ec2.Close()
+
+	assert.IsType(t, httpsource.StatusError{}, NewSource(WithEC2Endpoint(ec2.URL)).Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("metadata://")
+	require.NoError(t, err)
+	assert.Equal(t, ec2Endpoint, opened.(*source).ec2Endpoint)
+}
+
+func TestSourceKeys(t *testing.T) {
+	_, lists := interface{}(NewSource()).(migi.KeyLister)
+	assert.False(t, lists)
+}
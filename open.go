@@ -0,0 +1,100 @@
+package migi
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// SourceOpener creates the source described by an url, like env://?prefix=APP_
+type SourceOpener func(u *url.URL) (Source, error)
+
+var (
+	openersMutex sync.RWMutex
+	openers      = make(map[string]SourceOpener)
+)
+
+// RegisterSource makes OpenSource create the sources of urls with scheme using
+// opener. Source packages register their schemes when imported
+func RegisterSource(scheme string, opener SourceOpener) {
+	openersMutex.Lock()
+	defer openersMutex.Unlock()
+	if opener == nil {
+		delete(openers, scheme)
+		return
+	}
+	openers[scheme] = opener
+}
+
+// OpenSource creates the source described by rawURL with the opener registered for its scheme
+func OpenSource(rawURL string) (Source, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, err
+	}
+
+	openersMutex.RLock()
+	opener, ok := openers[u.Scheme]
+	openersMutex.RUnlock()
+	if !ok {
+		return nil, NewSchemeNotRegistered(u.Scheme)
+	}
+
+	return opener(u)
+}
+
+// OpenSources creates the sources described by a comma separated list of urls,
+// e.g. env://?prefix=APP_,file:///etc/app/config.json,consul://host/app.
+// Only commas starting a new url, scheme:// or a registered scheme:, separate
+// them, so commas within an url like ?keys=a,b are kept
+func OpenSources(rawURLs string) ([]Source, error) {
+	var sources []Source
+	for _, rawURL := range splitURLs(rawURLs) {
+		if strings.TrimSpace(rawURL) == "" {
+			continue
+		}
+		source, err := OpenSource(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
+
+var urlStart = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9+.-]*):(//)?`)
+
+// splitURLs splits the list at the commas followed by the start of an url
+func splitURLs(rawURLs string) []string {
+	var (
+		urls  []string
+		start int
+	)
+	for index := 0; index < len(rawURLs); index++ {
+		if rawURLs[index] != ',' || !startsURL(rawURLs[index+1:]) {
+			continue
+		}
+		urls = append(urls, rawURLs[start:index])
+		start = index + 1
+	}
+
+	return append(urls, rawURLs[start:])
+}
+
+func startsURL(value string) bool {
+	match := urlStart.FindStringSubmatch(value)
+	if match == nil {
+		return strings.TrimSpace(value) == ""
+	}
+	if match[2] != "" {
+		return true
+	}
+
+	openersMutex.RLock()
+	defer openersMutex.RUnlock()
+	_, ok := openers[match[1]]
+
+	return ok
+}
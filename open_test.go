@@ -0,0 +1,46 @@
+package migi
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSources(t *testing.T) {
+	var opened []string
+	RegisterSource("mock", func(u *url.URL) (Source, error) {
+		if u.Query().Get("fail") != "" {
+			return nil, errors.New("mock_open_error")
+		}
+		opened = append(opened, u.Host+u.Path)
+		return &mockSource{}, nil
+	})
+	defer RegisterSource("mock", nil)
+
+	sources, err := OpenSources("mock://host/app, mock:///etc/app/config.json,")
+	require.NoError(t, err)
+	assert.Len(t, sources, 2)
+	assert.Equal(t, []string{"host/app", "/etc/app/config.json"}, opened)
+
+	opened = nil
+	sources, err = OpenSources("mock://host/app?keys=a,b&field=x,mock:/etc/app,mock://host/other?host=a,host:8080")
+	require.NoError(t, err)
+	assert.Len(t, sources, 3)
+	assert.Equal(t, []string{"host/app", "/etc/app", "host/other"}, opened)
+
+	_, err = OpenSources("mock://host/app,zookeeper://host/app")
+	assert.EqualError(t, err, "errors.SchemeNotRegistered{Scheme='zookeeper'}")
+
+	_, err = OpenSource("mock://host/app?fail=true")
+	assert.EqualError(t, err, "mock_open_error")
+
+	_, err = OpenSource("mock://host:port/app")
+	assert.Error(t, err)
+
+	RegisterSource("mock", nil)
+	_, err = OpenSource("mock://host/app")
+	assert.IsType(t, SchemeNotRegistered{}, err)
+}
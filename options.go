@@ -24,6 +24,50 @@ type (
 		Load() error
 	}
 
+	// ProfileOptions is an optional Options interface to register the option
+	// selecting the active profiles, see Profile
+	ProfileOptions interface {
+		Profile(name string, defaultValue string, description string) *string
+	}
+
+	// ProvenanceOptions is an optional Options interface to describe the
+	// source that supplied the loaded value of an option
+	ProvenanceOptions interface {
+		Provenance(name string) string
+	}
+
+	// StagedOptions is an optional Options interface to load options in
+	// stages, whose sources depend on the previous stages, see Stage
+	StagedOptions interface {
+		Stage(factory SourceFactory)
+	}
+
+	// DeprecatedOptions is an optional Options interface to read options from
+	// their deprecated names and receive the warnings raised by Load
+	DeprecatedOptions interface {
+		Deprecate(name string, deprecated ...string)
+		OnWarning(handler WarningHandler)
+	}
+
+	// StrictOptions is an optional Options interface to report the unknown
+	// option names listed by the sources, see Strict
+	StrictOptions interface {
+		Strict(strict bool)
+	}
+
+	// InterpolatingOptions is an optional Options interface to expand the
+	// expressions found in string options, see Interpolate
+	InterpolatingOptions interface {
+		Interpolate(enabled bool)
+	}
+
+	// WarningHandler receives the warnings raised while loading options, like OptionDeprecated
+	WarningHandler func(warning error)
+
+	// SourceFactory creates the sources of a loading stage, it may read the
+	// options loaded by the previous stages
+	SourceFactory func() ([]Source, error)
+
 	// Source is an interface to define how options are loaded
 	Source interface {
 		Load() error
@@ -35,6 +79,29 @@ type (
 		String(name string) (string, error)
 	}
 
+	// Provenancer is an optional Source interface to describe where its options come from
+	Provenancer interface {
+		Provenance() string
+	}
+
+	// Valuer is an optional Source interface to read the raw value of an
+	// option, like a nested map or a slice, as the source holds it
+	Valuer interface {
+		Value(name string) (interface{}, error)
+	}
+
+	// KeyLister is an optional Source interface to list the option names it holds,
+	// strict options report the listed names not registered
+	KeyLister interface {
+		Keys() []string
+	}
+
+	// ProfileSource is an optional Source interface to provide profile specific
+	// overlays, it returns a nil Source when there is no overlay for the profile
+	ProfileSource interface {
+		Profile(name string) (Source, error)
+	}
+
 	// option is a configured value
 	option struct {
 		name         string
@@ -42,19 +109,34 @@ type (
 		defaultValue interface{}
 		pointer      interface{}
 		setted       bool
+		provenance   string
+		deprecated   []string
+		// deprecatedSetted are the deprecated names supplying a value on the last scan
+		deprecatedSetted []string
 	}
 
 	// options is a default Options implementation
 	options struct {
 		register []*option
 		sources  []Source
+		stages   []SourceFactory
+		profile  *option
+		// deprecations are the deprecated names of the options, by option name
+		deprecations map[string][]string
+		warning      WarningHandler
+		strict       bool
+		interpolate  bool
+		// overlays are the profile overlays loaded by the current Load
+		overlays map[overlayKey]*layer
 	}
 )
 
-func (o *option) scan(sources ...Source) []error {
+func (o *option) scan(layers ...layer) []error {
 	var errs []error
-	for _, source := range sources {
-		err := o.read(source)
+	o.setted = false
+	o.provenance = defaultProvenance
+	for _, layer := range layers {
+		err := o.read(layer.Source)
 		if err != nil {
 			if _, is := err.(OptionNotFound); !is {
 				errs = append(errs, err)
@@ -62,7 +144,9 @@ func (o *option) scan(sources ...Source) []error {
 			continue
 		}
 		o.setted = true
+		o.provenance = layer.provenance
 	}
+	errs = append(errs, o.scanDeprecated(layers)...)
 
 	if len(errs) > 0 {
 		return errs
@@ -251,9 +335,66 @@ func (o *options) DurationVar(pointer *time.Duration, name string, defaultValue
 	)
 }
 
-func (o *options) loadSources() error {
+// Profile registers the option selecting the active profiles, a comma separated list
+// read from the sources before their profile overlays are applied over them
+func (o *options) Profile(name string, defaultValue string, description string) *string {
+	pointer := o.String(name, defaultValue, description)
+	o.profile = o.register[len(o.register)-1]
+
+	return pointer
+}
+
+// Provenance describes the source that supplied the loaded value of the option
+func (o *options) Provenance(name string) string {
+	for index := len(o.register) - 1; index >= 0; index-- {
+		if o.register[index].name == name {
+			return o.register[index].provenance
+		}
+	}
+
+	return ""
+}
+
+// Stage adds a loading stage: once the options are loaded from the previous
+// stages sources, factory creates further sources, which may depend on the
+// loaded options, and every option is loaded again with them taking precedence
+func (o *options) Stage(factory SourceFactory) {
+	o.stages = append(o.stages, factory)
+}
+
+// Deprecate makes the option registered as name also read from its deprecated names.
+// Using a deprecated name raises an OptionDeprecated warning, setting both names with
+// different values fails the Load with OptionConflict. Load fails with OptionNotRegistered
+// when no option is registered as name
+func (o *options) Deprecate(name string, deprecated ...string) {
+	if o.deprecations == nil {
+		o.deprecations = make(map[string][]string)
+	}
+	o.deprecations[name] = append(o.deprecations[name], deprecated...)
+}
+
+// OnWarning sets the handler of the warnings raised by Load, which are dropped by default
+func (o *options) OnWarning(handler WarningHandler) {
+	o.warning = handler
+}
+
+// Strict makes Load fail with OptionUnknown for the option names listed by
+// KeyLister sources that are not registered, nor deprecated names
+func (o *options) Strict(strict bool) {
+	o.strict = strict
+}
+
+// Interpolate makes Load expand the ${name} expressions found in string options with the
+// loaded value of the name option, or the string the sources hold for unregistered names.
+// ${env:NAME} expands to an environment variable, ${name:-fallback} expands fallback when
+// name is not found and $${ is kept as a literal ${. Options are not interpolated by default
+func (o *options) Interpolate(enabled bool) {
+	o.interpolate = enabled
+}
+
+func (o *options) loadSources(sources []Source) error {
 	var errs []error
-	for _, source := range o.sources {
+	for _, source := range sources {
 		err := source.Load()
 		if err != nil {
 			errs = append(errs, err)
@@ -266,14 +407,60 @@ func (o *options) loadSources() error {
 }
 
 func (o *options) Load() error {
-	err := o.loadSources()
+	if err := o.deprecate(); err != nil {
+		return err
+	}
+	o.overlays = make(map[overlayKey]*layer)
+	sources := o.sources
+	layers, err := o.load(sources, sources)
 	if err != nil {
 		return err
 	}
 
+	for _, stage := range o.stages {
+		staged, err := stage()
+		if err != nil {
+			return err
+		}
+		if len(staged) == 0 {
+			continue
+		}
+		sources = append(sources[:len(sources):len(sources)], staged...)
+		if layers, err = o.load(staged, sources); err != nil {
+			return err
+		}
+	}
+	if o.interpolate {
+		if err := o.expand(layers); err != nil {
+			return err
+		}
+	}
+	o.warnDeprecated()
+
+	return nil
+}
+
+// load loads the new sources then every option from all the sources,
+// it returns the layers the options were loaded from
+func (o *options) load(loading []Source, sources []Source) ([]layer, error) {
+	err := o.loadSources(loading)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := o.layers(sources)
+	if err != nil {
+		return nil, err
+	}
+	if o.strict {
+		if errs := o.unknown(layers); len(errs) > 0 {
+			return nil, abend.NewList(errs...)
+		}
+	}
+
 	var errs []error
 	for _, option := range o.register {
-		scanErrs := option.scan(o.sources...)
+		scanErrs := option.scan(layers...)
 		if len(scanErrs) > 0 {
 			errs = append(errs, scanErrs...)
 			continue
@@ -285,10 +472,10 @@ func (o *options) Load() error {
 	}
 
 	if len(errs) > 0 {
-		return abend.NewList(errs...)
+		return nil, abend.NewList(errs...)
 	}
 
-	return nil
+	return layers, nil
 }
 
 // NewOptions creates an options instance with the provided sources
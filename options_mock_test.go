@@ -28,6 +28,10 @@ func (m mockSource) getValue(name string) (interface{}, error) {
 	return value, nil
 }
 
+func (m mockSource) Value(name string) (interface{}, error) {
+	return m.getValue(name)
+}
+
 func (m mockSource) String(name string) (string, error) {
 	value, err := m.getValue(name)
 	if err != nil {
@@ -99,3 +103,14 @@ func (m mockSource) Duration(name string) (time.Duration, error) {
 	}
 	return durationValue, nil
 }
+
+// loadCountingSource counts the loads of the wrapped source
+type loadCountingSource struct {
+	mockSource
+	loads int
+}
+
+func (l *loadCountingSource) Load() error {
+	l.loads++
+	return l.mockSource.Load()
+}
@@ -78,6 +78,84 @@ func TestOptions(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "when options hold references",
+			options: []testOption{
+				{name: "string_key", value: testutils.StringPointer("")},
+				{name: "int_key", value: testutils.IntPointer(0)},
+				{name: "float_key", value: testutils.FloatPointer(0.0)},
+				{name: "bool_key", value: testutils.BoolPointer(false)},
+				{name: "time_key", value: testutils.TimePointer(time.Time{})},
+				{name: "duration_key", value: testutils.DurationPointer(time.Duration(0))},
+				{name: "plain_key", value: testutils.StringPointer("")},
+			},
+			sources: []Source{
+				NewResolvingSource(&mockSource{
+					options: map[string]interface{}{
+						"string_key":   "base64:c3RyaW5nX3ZhbHVl",
+						"int_key":      "base64:MzMz",
+						"float_key":    "base64:MzMzLjMz",
+						"bool_key":     "base64:dHJ1ZQ==",
+						"time_key":     "base64:MTk5OS0xMC0wNVQwMDowMDowMFo=",
+						"duration_key": "base64:NW0=",
+						"plain_key":    "http://localhost:8080",
+					},
+				}),
+			},
+			expected: testOptionsExpected{
+				options: map[string]interface{}{
+					"string_key":   "string_value",
+					"int_key":      333,
+					"float_key":    float32(333.33),
+					"bool_key":     true,
+					"time_key":     testutils.NewTime(t, "2006-01-02", "1999-10-05"),
+					"duration_key": time.Minute * 5,
+					"plain_key":    "http://localhost:8080",
+				},
+			},
+		},
+		{
+			name: "when options hold references without resolving",
+			options: []testOption{
+				{name: "string_key", value: testutils.StringPointer("")},
+				{name: "env_key", value: testutils.StringPointer("")},
+			},
+			sources: []Source{
+				&mockSource{
+					options: map[string]interface{}{
+						"string_key": "base64:c3RyaW5nX3ZhbHVl",
+						"env_key":    "env://MIGI_NOT_SET",
+					},
+				},
+			},
+			expected: testOptionsExpected{
+				options: map[string]interface{}{
+					"string_key": "base64:c3RyaW5nX3ZhbHVl",
+					"env_key":    "env://MIGI_NOT_SET",
+				},
+			},
+		},
+		{
+			name: "when resolve option raises error",
+			options: []testOption{
+				{name: "string_key", value: testutils.StringPointer("")},
+				{name: "int_key", value: testutils.IntPointer(0)},
+			},
+			sources: []Source{
+				NewResolvingSource(&mockSource{
+					options: map[string]interface{}{
+						"string_key": "base64:!invalid",
+						"int_key":    "base64:bm90X2ludA==",
+					},
+				}),
+			},
+			expected: testOptionsExpected{
+				loadError: abend.NewList(
+					errors.New("errors.OptionNotResolved{Name='string_key', Scheme='base64', Cause='illegal base64 data at input byte 0'}"),
+					errors.New(`strconv.ParseInt: parsing "not_int": invalid syntax`),
+				),
+			},
+		},
 		{
 			name: "when load raises error",
 			sources: []Source{
@@ -204,3 +282,105 @@ func TestOptions(t *testing.T) {
 		)
 	}
 }
+
+func TestOptionsStage(t *testing.T) {
+	files := map[string]Source{
+		"/etc/app/config.json": &mockSource{
+			options: map[string]interface{}{
+				"CONSUL_ADDR": "consul.local:8500",
+				"db.host":     "file.local",
+				"db.port":     5432,
+			},
+		},
+	}
+	options := NewOptions(
+		&mockSource{
+			options: map[string]interface{}{
+				"CONFIG_FILE": "/etc/app/config.json",
+				"db.host":     "env.local",
+			},
+		},
+	)
+	configFile := options.String("CONFIG_FILE", "", "the config file")
+	consulAddr := options.String("CONSUL_ADDR", "", "the consul address")
+	host := options.String("db.host", "", "the database host")
+	port := options.Int("db.port", 0, "the database port")
+	user := options.String("db.user", "default_user", "the database user")
+
+	var consulAddrs []string
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		return []Source{files[*configFile]}, nil
+	})
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		consulAddrs = append(consulAddrs, *consulAddr)
+		return []Source{&mockSource{options: map[string]interface{}{"db.user": "consul_user"}}}, nil
+	})
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		return nil, nil
+	})
+	require.NoError(t, options.Load())
+
+	assert.Equal(t, "file.local", *host)
+	assert.Equal(t, 5432, *port)
+	assert.Equal(t, "consul_user", *user)
+	assert.Equal(t, []string{"consul.local:8500"}, consulAddrs)
+}
+
+func TestOptionsStageError(t *testing.T) {
+	options := NewOptions(&mockSource{})
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		return nil, errors.New("mock_stage_error")
+	})
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		t.Fatal("stage must not run after an error")
+		return nil, nil
+	})
+	assert.EqualError(t, options.Load(), "mock_stage_error")
+
+	options = NewOptions(&mockSource{})
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		return []Source{&mockSource{loadError: errors.New("mock_load_error")}}, nil
+	})
+	assert.EqualError(t, options.Load(), "errors.List{mock_load_error}")
+}
+
+func TestOptionsStageInterpolation(t *testing.T) {
+	options := NewOptions(
+		&mockSource{options: map[string]interface{}{"db.url": "postgres://${db.user}@${db.host}/app"}},
+	)
+	options.(InterpolatingOptions).Interpolate(true)
+	url := options.String("db.url", "", "the database url")
+	host := options.String("db.host", "localhost", "the database host")
+	options.(StagedOptions).Stage(func() ([]Source, error) {
+		return []Source{
+			&mockSource{options: map[string]interface{}{"db.user": "vault_user", "db.host": "db.local"}},
+		}, nil
+	})
+	require.NoError(t, options.Load())
+
+	assert.Equal(t, "postgres://vault_user@db.local/app", *url)
+	assert.Equal(t, "db.local", *host)
+}
+
+func TestOptionsStageProfileOverlays(t *testing.T) {
+	overlay := &loadCountingSource{mockSource: mockSource{options: map[string]interface{}{"db.host": "prod.local"}}}
+	options := NewOptions(
+		NewProfileSource(
+			&mockSource{options: map[string]interface{}{"APP_PROFILE": "prod", "db.host": "base.local"}},
+			map[string]Source{"prod": overlay},
+		),
+	)
+	options.(ProfileOptions).Profile("APP_PROFILE", "", "the active profiles")
+	host := options.String("db.host", "", "the database host")
+	for index := 0; index < 3; index++ {
+		options.(StagedOptions).Stage(func() ([]Source, error) {
+			return []Source{&mockSource{}}, nil
+		})
+	}
+	require.NoError(t, options.Load())
+	assert.Equal(t, "prod.local", *host)
+	assert.Equal(t, 1, overlay.loads)
+
+	require.NoError(t, options.Load())
+	assert.Equal(t, 2, overlay.loads)
+}
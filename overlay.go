@@ -0,0 +1,272 @@
+package migi
+
+import (
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// Labels describe the running process, like its region or hostname
+	Labels map[string]string
+
+	// Labeler provides the labels matched by overlay selectors
+	Labeler interface {
+		Labels() (Labels, error)
+	}
+
+	// LabelerFunc is a function implementing Labeler
+	LabelerFunc func() (Labels, error)
+
+	// Overlay is a source layered over the base one when its selector matches
+	// the labels. A selector is a comma separated list of label=pattern or
+	// label!=pattern terms, where patterns may hold shell wildcards, e.g.
+	// "region=eu-west-1,hostname=db-*". Every term must match
+	Overlay struct {
+		Selector string
+		Source   Source
+	}
+
+	// overlaySource serves the matching overlays, the most specific first, then the base source
+	overlaySource struct {
+		base     Source
+		labeler  Labeler
+		overlays []Overlay
+		active   []Source
+	}
+
+	selectorTerm struct {
+		label    string
+		pattern  string
+		negated  bool
+		wildcard bool
+	}
+)
+
+func (f LabelerFunc) Labels() (Labels, error) {
+	return f()
+}
+
+// StaticLabels returns a Labeler always providing labels
+func StaticLabels(labels Labels) Labeler {
+	return LabelerFunc(func() (Labels, error) {
+		return labels, nil
+	})
+}
+
+// SourceLabels returns a Labeler reading each label from the option, mapped
+// by the label name, of an already loaded source. Missing options are not labels
+func SourceLabels(source Source, options map[string]string) Labeler {
+	return LabelerFunc(func() (Labels, error) {
+		labels := make(Labels, len(options))
+		for label, name := range options {
+			value, err := source.String(name)
+			if err != nil {
+				if _, is := err.(OptionNotFound); is {
+					continue
+				}
+				return nil, err
+			}
+			labels[label] = value
+		}
+		return labels, nil
+	})
+}
+
+// EnvironmentLabels returns a Labeler reading each label from the environment
+// variable mapped by the label name. The hostname label, unless mapped, holds the host name
+func EnvironmentLabels(variables map[string]string) Labeler {
+	return LabelerFunc(func() (Labels, error) {
+		labels := make(Labels, len(variables)+1)
+		if _, mapped := variables["hostname"]; !mapped {
+			hostname, err := os.Hostname()
+			if err != nil {
+				return nil, err
+			}
+			labels["hostname"] = hostname
+		}
+		for label, variable := range variables {
+			if value, ok := os.LookupEnv(variable); ok {
+				labels[label] = value
+			}
+		}
+		return labels, nil
+	})
+}
+
+// NewOverlaySource creates a source serving base overlaid by the overlays
+// whose selectors match the labels. When several overlays hold an option the
+// most specific one wins: the one with more terms, then with fewer wildcard
+// terms, then the one declared last
+func NewOverlaySource(base Source, labeler Labeler, overlays ...Overlay) Source {
+	return &overlaySource{
+		base:     base,
+		labeler:  labeler,
+		overlays: overlays,
+	}
+}
+
+func parseSelector(selector string) ([]selectorTerm, error) {
+	var terms []selectorTerm
+	for _, raw := range strings.Split(selector, ",") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		index := strings.Index(raw, "=")
+		if index <= 0 {
+			return nil, NewSelectorInvalid(selector, raw)
+		}
+		term := selectorTerm{
+			label:   strings.TrimSpace(raw[:index]),
+			pattern: strings.TrimSpace(raw[index+1:]),
+		}
+		if strings.HasSuffix(term.label, "!") {
+			term.label = strings.TrimSpace(strings.TrimSuffix(term.label, "!"))
+			term.negated = true
+		}
+		if _, err := path.Match(term.pattern, ""); err != nil || term.label == "" {
+			return nil, NewSelectorInvalid(selector, raw)
+		}
+		term.wildcard = strings.ContainsAny(term.pattern, "*?[")
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
+func (t selectorTerm) match(labels Labels) bool {
+	value, ok := labels[t.label]
+	matched, _ := path.Match(t.pattern, value)
+	matched = ok && matched
+
+	return matched != t.negated
+}
+
+func (s *overlaySource) Load() error {
+	if err := s.base.Load(); err != nil {
+		return err
+	}
+	labels, err := s.labeler.Labels()
+	if err != nil {
+		return err
+	}
+
+	type candidate struct {
+		source    Source
+		terms     int
+		wildcards int
+		index     int
+	}
+	var matches []candidate
+	for index, overlay := range s.overlays {
+		terms, err := parseSelector(overlay.Selector)
+		if err != nil {
+			return err
+		}
+		matched := candidate{source: overlay.Source, terms: len(terms), index: index}
+		for _, term := range terms {
+			if !term.match(labels) {
+				matched.source = nil
+				break
+			}
+			if term.wildcard {
+				matched.wildcards++
+			}
+		}
+		if matched.source == nil {
+			continue
+		}
+		if err := overlay.Source.Load(); err != nil {
+			return err
+		}
+		matches = append(matches, matched)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].terms != matches[j].terms {
+			return matches[i].terms > matches[j].terms
+		}
+		if matches[i].wildcards != matches[j].wildcards {
+			return matches[i].wildcards < matches[j].wildcards
+		}
+		return matches[i].index > matches[j].index
+	})
+	s.active = make([]Source, len(matches))
+	for index, match := range matches {
+		s.active[index] = match.source
+	}
+
+	return nil
+}
+
+// lookup calls get on the active overlays, then on the base source, until one holds the option
+func (s *overlaySource) lookup(get func(Source) error) error {
+	for _, source := range s.active {
+		err := get(source)
+		if _, is := err.(OptionNotFound); !is {
+			return err
+		}
+	}
+
+	return get(s.base)
+}
+
+// Keys lists the option names of the base and the matching overlays implementing KeyLister
+func (s *overlaySource) Keys() []string {
+	return sortedKeys(append([]Source{s.base}, s.active...)...)
+}
+
+func (s *overlaySource) Provenance() string {
+	return provenance(s.base)
+}
+
+func (s *overlaySource) String(name string) (value string, err error) {
+	err = s.lookup(func(source Source) (err error) {
+		value, err = source.String(name)
+		return err
+	})
+	return value, err
+}
+
+func (s *overlaySource) Int(name string) (value int, err error) {
+	err = s.lookup(func(source Source) (err error) {
+		value, err = source.Int(name)
+		return err
+	})
+	return value, err
+}
+
+func (s *overlaySource) Float(name string) (value float32, err error) {
+	err = s.lookup(func(source Source) (err error) {
+		value, err = source.Float(name)
+		return err
+	})
+	return value, err
+}
+
+func (s *overlaySource) Bool(name string) (value bool, err error) {
+	err = s.lookup(func(source Source) (err error) {
+		value, err = source.Bool(name)
+		return err
+	})
+	return value, err
+}
+
+func (s *overlaySource) Time(name string) (value time.Time, err error) {
+	err = s.lookup(func(source Source) (err error) {
+		value, err = source.Time(name)
+		return err
+	})
+	return value, err
+}
+
+func (s *overlaySource) Duration(name string) (value time.Duration, err error) {
+	err = s.lookup(func(source Source) (err error) {
+		value, err = source.Duration(name)
+		return err
+	})
+	return value, err
+}
@@ -0,0 +1,125 @@
+package migi
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverlaySource(t *testing.T) {
+	overlays := []Overlay{
+		{Selector: "region=eu-*", Source: &mockSource{options: map[string]interface{}{"db.host": "eu.local", "db.pool": 10}}},
+		{Selector: "region=eu-west-1", Source: &mockSource{options: map[string]interface{}{"db.host": "eu-west-1.local"}}},
+		{Selector: "region=eu-west-1, hostname=db-*", Source: &mockSource{options: map[string]interface{}{"db.host": "db.eu-west-1.local"}}},
+		{Selector: "region=us-east-1", Source: &mockSource{options: map[string]interface{}{"db.host": "us.local"}}},
+		{Selector: "region!=us-*", Source: &mockSource{options: map[string]interface{}{"db.timeout": time.Second * 10}}},
+	}
+	scenarios := []struct {
+		name     string
+		labels   Labels
+		expected map[string]interface{}
+	}{
+		{
+			name:   "when the most specific overlay matches",
+			labels: Labels{"region": "eu-west-1", "hostname": "db-01"},
+			expected: map[string]interface{}{
+				"db.host":    "db.eu-west-1.local",
+				"db.pool":    10,
+				"db.timeout": time.Second * 10,
+				"db.user":    "base_user",
+			},
+		},
+		{
+			name:   "when exact and wildcard overlays match",
+			labels: Labels{"region": "eu-west-1", "hostname": "app-01"},
+			expected: map[string]interface{}{
+				"db.host": "eu-west-1.local",
+				"db.pool": 10,
+			},
+		},
+		{
+			name:   "when a negated term excludes an overlay",
+			labels: Labels{"region": "us-east-1"},
+			expected: map[string]interface{}{
+				"db.host":    "us.local",
+				"db.pool":    1,
+				"db.timeout": time.Second,
+			},
+		},
+		{
+			name:   "when no overlay matches",
+			labels: Labels{},
+			expected: map[string]interface{}{
+				"db.host":    "base.local",
+				"db.timeout": time.Second * 10,
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				source := NewOverlaySource(
+					&mockSource{options: map[string]interface{}{
+						"db.host":    "base.local",
+						"db.pool":    1,
+						"db.user":    "base_user",
+						"db.timeout": time.Second,
+					}},
+					StaticLabels(scenario.labels),
+					overlays...,
+				)
+				require.NoError(t, source.Load())
+
+				for key, value := range scenario.expected {
+					switch value.(type) {
+					case string:
+						v, err := source.String(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v, key)
+					case int:
+						v, err := source.Int(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v, key)
+					case time.Duration:
+						v, err := source.Duration(key)
+						assert.NoError(t, err)
+						assert.Equal(t, value, v, key)
+					}
+				}
+				_, err := source.String("missing.key")
+				assert.IsType(t, OptionNotFound{}, err)
+			},
+		)
+	}
+}
+
+func TestOverlaySourceError(t *testing.T) {
+	source := NewOverlaySource(&mockSource{}, StaticLabels(Labels{}), Overlay{Selector: "region", Source: &mockSource{}})
+	assert.EqualError(t, source.Load(), "errors.SelectorInvalid{Selector='region', Term='region'}")
+
+	source = NewOverlaySource(&mockSource{}, StaticLabels(Labels{}), Overlay{Selector: "region=[", Source: &mockSource{}})
+	assert.IsType(t, SelectorInvalid{}, source.Load())
+}
+
+func TestLabelers(t *testing.T) {
+	os.Setenv("MIGI_OVERLAY_REGION", "eu-west-1")
+	defer os.Unsetenv("MIGI_OVERLAY_REGION")
+
+	labels, err := EnvironmentLabels(map[string]string{"region": "MIGI_OVERLAY_REGION", "zone": "MIGI_OVERLAY_ZONE"}).Labels()
+	require.NoError(t, err)
+	hostname, _ := os.Hostname()
+	assert.Equal(t, Labels{"region": "eu-west-1", "hostname": hostname}, labels)
+
+	labels, err = SourceLabels(
+		&mockSource{options: map[string]interface{}{"cloud.region": "us-east-1"}},
+		map[string]string{"region": "cloud.region", "zone": "cloud.zone"},
+	).Labels()
+	require.NoError(t, err)
+	assert.Equal(t, Labels{"region": "us-east-1"}, labels)
+}
@@ -0,0 +1,154 @@
+package migi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rjansen/abend"
+)
+
+const defaultProvenance = "default"
+
+type (
+	// layer is a loaded source scanned for options, in precedence order
+	layer struct {
+		Source
+		provenance string
+	}
+
+	// overlayKey identifies the overlay of the source at an index for a profile
+	overlayKey struct {
+		source  int
+		profile string
+	}
+
+	// profileSource adds profile overlays to a source
+	profileSource struct {
+		Source
+		profiles map[string]Source
+	}
+)
+
+func (p *profileSource) Profile(name string) (Source, error) {
+	return p.profiles[name], nil
+}
+
+func (p *profileSource) Keys() []string {
+	return sortedKeys(p.Source)
+}
+
+func (p *profileSource) Provenance() string {
+	return provenance(p.Source)
+}
+
+// NewProfileSource creates a source serving base, overlaid by the profiles
+// sources whose names are active
+func NewProfileSource(base Source, profiles map[string]Source) Source {
+	return &profileSource{Source: base, profiles: profiles}
+}
+
+func provenance(source Source) string {
+	if provenancer, is := source.(Provenancer); is {
+		return provenancer.Provenance()
+	}
+
+	return fmt.Sprintf("%T", source)
+}
+
+func layerSources(layers []layer) []Source {
+	sources := make([]Source, len(layers))
+	for index, layer := range layers {
+		sources[index] = layer.Source
+	}
+
+	return sources
+}
+
+// profiles reads the active profiles from the layers
+func (o *options) profiles(layers []layer) ([]string, error) {
+	if o.profile == nil {
+		return nil, nil
+	}
+	if errs := o.profile.scan(layers...); len(errs) > 0 {
+		return nil, abend.NewList(errs...)
+	}
+	if !o.profile.setted {
+		o.profile.setDefault()
+	}
+
+	var profiles []string
+	for _, profile := range strings.Split(*o.profile.pointer.(*string), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+
+	return profiles, nil
+}
+
+// layers returns the sources, each one followed by its overlays of the active profiles
+func (o *options) layers(sources []Source) ([]layer, error) {
+	base := make([]layer, len(sources))
+	for index, source := range sources {
+		base[index] = layer{Source: source, provenance: provenance(source)}
+	}
+
+	profiles, err := o.profiles(base)
+	if err != nil || len(profiles) == 0 {
+		return base, err
+	}
+
+	var (
+		layers []layer
+		errs   []error
+	)
+	for index, source := range base {
+		layers = append(layers, source)
+		for _, profile := range profiles {
+			overlay, err := o.overlay(index, source.Source, profile)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if overlay != nil {
+				layers = append(layers, *overlay)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, abend.NewList(errs...)
+	}
+
+	return layers, nil
+}
+
+// overlay returns the loaded profile overlay of the source at index, if any.
+// Overlays are opened and loaded once per Load and reused by the later stages
+func (o *options) overlay(index int, source Source, profile string) (*layer, error) {
+	key := overlayKey{source: index, profile: profile}
+	if overlay, ok := o.overlays[key]; ok {
+		return overlay, nil
+	}
+	profileSource, is := source.(ProfileSource)
+	if !is {
+		return nil, nil
+	}
+
+	overlaySource, err := profileSource.Profile(profile)
+	if err != nil {
+		return nil, err
+	}
+	var overlay *layer
+	if overlaySource != nil {
+		if err := overlaySource.Load(); err != nil {
+			return nil, err
+		}
+		overlay = &layer{
+			Source:     overlaySource,
+			provenance: fmt.Sprintf("%s (profile %s)", provenance(overlaySource), profile),
+		}
+	}
+	o.overlays[key] = overlay
+
+	return overlay, nil
+}
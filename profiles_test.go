@@ -0,0 +1,92 @@
+package migi
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type provenanceMockSource struct {
+	mockSource
+	provenance string
+}
+
+func (p provenanceMockSource) Provenance() string {
+	return p.provenance
+}
+
+func TestOptionsProfile(t *testing.T) {
+	base := &provenanceMockSource{
+		provenance: "base.json",
+		mockSource: mockSource{
+			options: map[string]interface{}{
+				"db.host": "base.local",
+				"db.port": 5432,
+				"db.user": "base_user",
+			},
+		},
+	}
+	environment := &mockSource{
+		options: map[string]interface{}{
+			"APP_PROFILE": "staging, eu",
+		},
+	}
+	options := NewOptions(
+		NewProfileSource(base, map[string]Source{
+			"staging": &provenanceMockSource{
+				provenance: "staging.json",
+				mockSource: mockSource{options: map[string]interface{}{"db.host": "staging.local", "db.user": "staging_user"}},
+			},
+			"eu":   &mockSource{options: map[string]interface{}{"db.host": "eu.staging.local"}},
+			"prod": &mockSource{options: map[string]interface{}{"db.host": "prod.local"}},
+		}),
+		environment,
+	)
+
+	profile := options.(ProfileOptions).Profile("APP_PROFILE", "dev", "the active profiles")
+	host := options.String("db.host", "", "the database host")
+	port := options.Int("db.port", 0, "the database port")
+	user := options.String("db.user", "", "the database user")
+	timeout := options.Int("db.timeout", 5, "the database timeout")
+	require.NoError(t, options.Load())
+
+	assert.Equal(t, "staging, eu", *profile)
+	assert.Equal(t, "eu.staging.local", *host)
+	assert.Equal(t, 5432, *port)
+	assert.Equal(t, "staging_user", *user)
+	assert.Equal(t, 5, *timeout)
+
+	assert.Equal(t, "*migi.mockSource", options.(ProvenanceOptions).Provenance("APP_PROFILE"))
+	assert.Equal(t, "*migi.mockSource (profile eu)", options.(ProvenanceOptions).Provenance("db.host"))
+	assert.Equal(t, "base.json", options.(ProvenanceOptions).Provenance("db.port"))
+	assert.Equal(t, "staging.json (profile staging)", options.(ProvenanceOptions).Provenance("db.user"))
+	assert.Equal(t, "default", options.(ProvenanceOptions).Provenance("db.timeout"))
+	assert.Equal(t, "", options.(ProvenanceOptions).Provenance("missing.key"))
+}
+
+func TestOptionsProfileDefault(t *testing.T) {
+	options := NewOptions(
+		NewProfileSource(
+			&mockSource{options: map[string]interface{}{"db.host": "base.local"}},
+			map[string]Source{"dev": &mockSource{options: map[string]interface{}{"db.host": "dev.local"}}},
+		),
+	)
+	options.(ProfileOptions).Profile("APP_PROFILE", "dev", "the active profiles")
+	host := options.String("db.host", "", "the database host")
+	require.NoError(t, options.Load())
+
+	assert.Equal(t, "dev.local", *host)
+}
+
+func TestOptionsProfileError(t *testing.T) {
+	options := NewOptions(
+		NewProfileSource(
+			&mockSource{options: map[string]interface{}{"APP_PROFILE": "prod"}},
+			map[string]Source{"prod": &mockSource{loadError: errors.New("mock_profile_error")}},
+		),
+	)
+	options.(ProfileOptions).Profile("APP_PROFILE", "", "the active profiles")
+	assert.EqualError(t, options.Load(), "errors.List{mock_profile_error}")
+}
@@ -0,0 +1,37 @@
+package redis
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("redis", open)
+}
+
+// open creates the source of redis://:password@host:6379/0?hash=features
+// or redis://host:6379/0?prefix=app:
+func open(u *url.URL) (migi.Source, error) {
+	var options []Option
+	if password, ok := u.User.Password(); ok {
+		options = append(options, WithPassword(password))
+	}
+	if database := strings.Trim(u.Path, "/"); database != "" {
+		number, err := strconv.Atoi(database)
+		if err != nil {
+			return nil, fmt.Errorf("redis: invalid database %s", database)
+		}
+		options = append(options, WithDatabase(number))
+	}
+
+	query := u.Query()
+	if hash := query.Get("hash"); hash != "" {
+		return NewHashSource(u.Host, hash, options...), nil
+	}
+
+	return NewPrefixSource(u.Host, query.Get("prefix"), options...), nil
+}
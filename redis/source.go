@@ -0,0 +1,248 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+type (
+	// Option customizes the redis source
+	Option func(*source)
+
+	conn struct {
+		net.Conn
+		reader *bufio.Reader
+		writer *bufio.Writer
+	}
+
+	source struct {
+		*mapsource.Source
+		address  string
+		password string
+		database int
+		timeout  time.Duration
+		key      string
+		prefix   string
+	}
+)
+
+// WithPassword authenticates every connection
+func WithPassword(password string) Option {
+	return func(s *source) {
+		s.password = password
+	}
+}
+
+// WithDatabase selects the logical database, default 0
+func WithDatabase(database int) Option {
+	return func(s *source) {
+		s.database = database
+	}
+}
+
+// WithTimeout limits the time to connect and to exchange commands, default 5s
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *source) {
+		s.timeout = timeout
+	}
+}
+
+func (c *conn) do(args ...string) (interface{}, error) {
+	if err := writeCommand(c.writer, args...); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+func (s *source) dial() (*conn, error) {
+	netConn, err := net.DialTimeout("tcp", s.address, s.timeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &conn{Conn: netConn, reader: bufio.NewReader(netConn), writer: bufio.NewWriter(netConn)}
+	c.SetDeadline(time.Now().Add(s.timeout))
+
+	if s.password != "" {
+		if _, err := c.do("AUTH", s.password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if s.database != 0 {
+		if _, err := c.do("SELECT", strconv.Itoa(s.database)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (s *source) Load() error {
+	c, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	var values map[string]interface{}
+	if s.key != "" {
+		values, err = s.loadHash(c)
+	} else {
+		values, err = s.loadPrefix(c)
+	}
+	if err != nil {
+		return err
+	}
+	s.Set(values)
+
+	return nil
+}
+
+// Keys lists the option names of the hash fields or prefixed keys, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+func (s *source) loadHash(c *conn) (map[string]interface{}, error) {
+	reply, err := c.do("HGETALL", s.key)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := reply.([]interface{})
+	values := make(map[string]interface{}, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		field, _ := items[i].(string)
+		values[field] = items[i+1]
+	}
+
+	return values, nil
+}
+
+func (s *source) loadPrefix(c *conn) (map[string]interface{}, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := c.do("SCAN", cursor, "MATCH", s.prefix+"*", "COUNT", "100")
+		if err != nil {
+			return nil, err
+		}
+		page, ok := reply.([]interface{})
+		if !ok || len(page) != 2 {
+			return nil, fmt.Errorf("redis: invalid scan reply %v", reply)
+		}
+		cursor, _ = page[0].(string)
+		batch, _ := page[1].([]interface{})
+		for _, key := range batch {
+			keys = append(keys, key.(string))
+		}
+		if cursor == "0" {
+			break
+		}
+	}
+
+	values := make(map[string]interface{}, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+	reply, err := c.do(append([]string{"MGET"}, keys...)...)
+	if err != nil {
+		return nil, err
+	}
+	items, _ := reply.([]interface{})
+	for i, item := range items {
+		// MGET answers nil for keys that are not strings or were deleted meanwhile
+		if value, ok := item.(string); ok && i < len(keys) {
+			name := strings.TrimPrefix(keys[i], s.prefix)
+			values[strings.Replace(name, ":", ".", -1)] = value
+		}
+	}
+
+	return values, nil
+}
+
+// Watch subscribes to keyspace notifications of the hash or prefix, which
+// require notify-keyspace-events on the server, reloading the values and
+// calling changed on each notification until ctx is done
+func (s *source) Watch(ctx context.Context, changed func()) error {
+	c, err := s.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	pattern := fmt.Sprintf("__keyspace@%d__:%s", s.database, s.key)
+	if s.key == "" {
+		pattern += s.prefix + "*"
+	}
+	if _, err := c.do("PSUBSCRIBE", pattern); err != nil {
+		return err
+	}
+
+	c.SetDeadline(time.Time{})
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			c.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+
+	for {
+		reply, err := readReply(c.reader)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		if message, ok := reply.([]interface{}); !ok || len(message) == 0 || message[0] != "pmessage" {
+			continue
+		}
+		if err := s.Load(); err != nil {
+			return err
+		}
+		if changed != nil {
+			changed()
+		}
+	}
+}
+
+func newSource(address string, options ...Option) *source {
+	s := &source{
+		Source:  mapsource.New(nil),
+		address: address,
+		timeout: 5 * time.Second,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
+
+// NewHashSource creates a source where each field of the hash stored at key is an option
+func NewHashSource(address string, key string, options ...Option) *source {
+	s := newSource(address, options...)
+	s.key = key
+
+	return s
+}
+
+// NewPrefixSource creates a source where each string key starting with prefix
+// is an option named by the rest of the key, with colons replaced by dots
+func NewPrefixSource(address string, prefix string, options ...Option) *source {
+	s := newSource(address, options...)
+	s.prefix = prefix
+
+	return s
+}
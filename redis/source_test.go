@@ -0,0 +1,255 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer is an in-process fake speaking the resp commands used by the source
+type testServer struct {
+	listener net.Listener
+	password string
+	mutex    sync.Mutex
+	hashes   map[string]map[string]string
+	strings  map[string]string
+	commands []string
+	notify   chan string
+}
+
+func newTestServer(t *testing.T) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &testServer{
+		listener: listener,
+		hashes:   make(map[string]map[string]string),
+		strings:  make(map[string]string),
+		notify:   make(chan string, 1),
+	}
+	go server.serve()
+
+	return server
+}
+
+// received copies the commands read so far
+func (s *testServer) received() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return append([]string(nil), s.commands...)
+}
+
+func (s *testServer) serve() {
+	for {
+		c, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(c)
+	}
+}
+
+func (s *testServer) handle(c net.Conn) {
+	defer c.Close()
+	reader := bufio.NewReader(c)
+	writer := bufio.NewWriter(c)
+	for {
+		request, err := readReply(reader)
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range request.([]interface{}) {
+			args = append(args, arg.(string))
+		}
+		s.mutex.Lock()
+		s.commands = append(s.commands, strings.Join(args, " "))
+		s.reply(writer, args)
+		s.mutex.Unlock()
+		writer.Flush()
+		if args[0] == "PSUBSCRIBE" {
+			for key := range s.notify {
+				fmt.Fprintf(writer, "*4\r\n$8\r\npmessage\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n$4\r\nhset\r\n",
+					len(args[1]), args[1], len(key), key)
+				writer.Flush()
+			}
+			return
+		}
+	}
+}
+
+func bulk(writer *bufio.Writer, value string) {
+	fmt.Fprintf(writer, "$%d\r\n%s\r\n", len(value), value)
+}
+
+func (s *testServer) reply(writer *bufio.Writer, args []string) {
+	switch args[0] {
+	case "AUTH":
+		if args[1] != s.password {
+			writer.WriteString("-WRONGPASS invalid password\r\n")
+			return
+		}
+		writer.WriteString("+OK\r\n")
+	case "SELECT":
+		writer.WriteString("+OK\r\n")
+	case "HGETALL":
+		hash := s.hashes[args[1]]
+		fmt.Fprintf(writer, "*%d\r\n", len(hash)*2)
+		for field, value := range hash {
+			bulk(writer, field)
+			bulk(writer, value)
+		}
+	case "SCAN":
+		// answer one key per page to exercise the cursor
+		var keys []string
+		for key := range s.strings {
+			if strings.HasPrefix(key, strings.TrimSuffix(args[3], "*")) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		index := 0
+		fmt.Sscanf(args[1], "%d", &index)
+		next := "0"
+		if index+1 < len(keys) {
+			next = fmt.Sprint(index + 1)
+		}
+		fmt.Fprintf(writer, "*2\r\n")
+		bulk(writer, next)
+		if index < len(keys) {
+			fmt.Fprintf(writer, "*1\r\n")
+			bulk(writer, keys[index])
+		} else {
+			fmt.Fprintf(writer, "*0\r\n")
+		}
+	case "MGET":
+		fmt.Fprintf(writer, "*%d\r\n", len(args)-1)
+		for _, key := range args[1:] {
+			bulk(writer, s.strings[key])
+		}
+	case "PSUBSCRIBE":
+		writer.WriteString("*3\r\n$10\r\npsubscribe\r\n")
+		bulk(writer, args[1])
+		writer.WriteString(":1\r\n")
+	default:
+		writer.WriteString("-ERR unknown command\r\n")
+	}
+}
+
+func (s *testServer) Close() {
+	close(s.notify)
+	s.listener.Close()
+}
+
+func TestHashSource(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	server.password = "my_password"
+	server.hashes["features"] = map[string]string{
+		"string_key":   "string_value",
+		"bool_key":     "true",
+		"duration_key": "5m",
+	}
+
+	source := NewHashSource(server.listener.Addr().String(), "features", WithPassword("my_password"), WithDatabase(2))
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	boolValue, err := source.Bool("bool_key")
+	assert.NoError(t, err)
+	assert.True(t, boolValue)
+	durationValue, err := source.Duration("duration_key")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute*5, durationValue)
+	assert.Equal(t, []string{"AUTH my_password", "SELECT 2", "HGETALL features"}, server.received())
+
+	server.mutex.Lock()
+	server.hashes["features"]["string_key"] = "new_value"
+	server.mutex.Unlock()
+	server.notify <- "features"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err = source.Watch(ctx, cancel)
+	assert.NoError(t, err)
+	assert.Contains(t, server.received(), "PSUBSCRIBE __keyspace@2__:features")
+
+	stringValue, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "new_value", stringValue)
+}
+
+func TestPrefixSource(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	server.strings = map[string]string{
+		"app:string_key": "string_value",
+		"app:db:port":    "5432",
+		"other:key":      "other_value",
+	}
+
+	source := NewPrefixSource(server.listener.Addr().String(), "app:")
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	intValue, err := source.Int("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, intValue)
+	_, err = source.String("other.key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+}
+
+func TestSourceError(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	server.password = "my_password"
+
+	err := NewHashSource(server.listener.Addr().String(), "features", WithPassword("invalid")).Load()
+	assert.EqualError(t, err, "redis.ServerError{Message='WRONGPASS invalid password'}")
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("redis://:my_password@redis.local:6379/2?hash=features")
+	require.NoError(t, err)
+	openedSource := opened.(*source)
+	assert.Equal(t, "redis.local:6379", openedSource.address)
+	assert.Equal(t, "my_password", openedSource.password)
+	assert.Equal(t, 2, openedSource.database)
+	assert.Equal(t, "features", openedSource.key)
+
+	opened, err = migi.OpenSource("redis://redis.local:6379?prefix=app:")
+	require.NoError(t, err)
+	assert.Equal(t, "app:", opened.(*source).prefix)
+
+	_, err = migi.OpenSource("redis://redis.local:6379/db")
+	assert.EqualError(t, err, "redis: invalid database db")
+}
+
+func TestSourceKeys(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+	server.hashes["features"] = map[string]string{"string_key": "string_value", "bool_key": "true"}
+	server.strings = map[string]string{"app:db:host": "db.local", "other:key": "other_value"}
+
+	source := NewHashSource(server.listener.Addr().String(), "features")
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"bool_key", "string_key"}, source.Keys())
+
+	source = NewPrefixSource(server.listener.Addr().String(), "app:")
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"db.host"}, source.Keys())
+}
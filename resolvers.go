@@ -0,0 +1,234 @@
+package migi
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rjansen/migi/internal/parse"
+)
+
+type (
+	// Resolver turns a reference found in an option value, like
+	// file:///run/secrets/db_password, into the actual value
+	Resolver interface {
+		Resolve(reference string) (string, error)
+	}
+
+	// ResolverFunc is a function implementing Resolver
+	ResolverFunc func(reference string) (string, error)
+
+	// resolvingSource resolves references returned by the wrapped source
+	// before they are converted to the option type
+	resolvingSource struct {
+		Source
+	}
+)
+
+var (
+	resolversMutex sync.RWMutex
+	resolvers      = map[string]Resolver{
+		"file":   ResolverFunc(resolveFile),
+		"env":    ResolverFunc(resolveEnv),
+		"base64": ResolverFunc(resolveBase64),
+	}
+)
+
+func (f ResolverFunc) Resolve(reference string) (string, error) {
+	return f(reference)
+}
+
+// RegisterResolver makes resolver handle every option value starting with
+// scheme followed by a colon, replacing any resolver previously registered for it.
+// file: and env:, followed or not by //, and base64: are registered by default
+func RegisterResolver(scheme string, resolver Resolver) {
+	resolversMutex.Lock()
+	defer resolversMutex.Unlock()
+	if resolver == nil {
+		delete(resolvers, scheme)
+		return
+	}
+	resolvers[scheme] = resolver
+}
+
+// NewResolvingSource wraps source so that its string values referencing a
+// registered scheme, like file:///run/secrets/db_password, are replaced by the
+// resolved value before they are converted to the option type. Sources not
+// wrapped serve their values as they are
+func NewResolvingSource(source Source) Source {
+	return resolvingSource{Source: source}
+}
+
+// resolver returns the resolver registered for the scheme of value, if any
+func resolver(value string) (string, Resolver, bool) {
+	index := strings.Index(value, ":")
+	if index <= 0 {
+		return "", nil, false
+	}
+	scheme := value[:index]
+
+	resolversMutex.RLock()
+	defer resolversMutex.RUnlock()
+	resolver, ok := resolvers[scheme]
+
+	return scheme, resolver, ok
+}
+
+// resolve returns value itself when it is not a reference to a registered scheme
+func resolve(name string, value string) (string, bool, error) {
+	scheme, resolver, ok := resolver(value)
+	if !ok {
+		return value, false, nil
+	}
+
+	resolved, err := resolver.Resolve(value)
+	if err != nil {
+		return "", true, NewOptionNotResolved(name, scheme, err)
+	}
+
+	return resolved, true, nil
+}
+
+// referenced returns the reference without its scheme and the optional // after
+// it, so file:///run/secret, file:relative.txt and env:HOME are all handled
+func referenced(reference string, scheme string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(reference, scheme+":"), "//")
+}
+
+func resolveFile(reference string) (string, error) {
+	content, err := ioutil.ReadFile(referenced(reference, "file"))
+	if err != nil {
+		return "", err
+	}
+
+	return parse.TrimNewline(string(content)), nil
+}
+
+func resolveEnv(reference string) (string, error) {
+	name := referenced(reference, "env")
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	return value, nil
+}
+
+func resolveBase64(reference string) (string, error) {
+	value, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(reference, "base64:"))
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+func (r resolvingSource) Provenance() string {
+	return provenance(r.Source)
+}
+
+func (r resolvingSource) Profile(name string) (Source, error) {
+	return decoratedProfile(r.Source, NewResolvingSource, name)
+}
+
+func (r resolvingSource) Keys() []string {
+	return sortedKeys(r.Source)
+}
+
+// Value resolves the raw string values, any other raw value is returned as it is
+func (r resolvingSource) Value(name string) (interface{}, error) {
+	value, err := rawValue(r.Source, name)
+	if err != nil {
+		return nil, err
+	}
+	if stringValue, is := value.(string); is {
+		resolved, _, err := resolve(name, stringValue)
+		return resolved, err
+	}
+
+	return value, nil
+}
+
+// reference returns the resolved value when the source holds a string reference for name
+func (r resolvingSource) reference(name string) (string, bool, error) {
+	value, err := r.Source.String(name)
+	if err != nil {
+		return "", false, nil
+	}
+
+	return resolve(name, value)
+}
+
+func (r resolvingSource) String(name string) (string, error) {
+	value, err := r.Source.String(name)
+	if err != nil {
+		return "", err
+	}
+	resolved, _, err := resolve(name, value)
+
+	return resolved, err
+}
+
+func (r resolvingSource) Int(name string) (int, error) {
+	value, is, err := r.reference(name)
+	if err != nil {
+		return 0, err
+	}
+	if !is {
+		return r.Source.Int(name)
+	}
+
+	return parse.Int(value)
+}
+
+func (r resolvingSource) Float(name string) (float32, error) {
+	value, is, err := r.reference(name)
+	if err != nil {
+		return 0, err
+	}
+	if !is {
+		return r.Source.Float(name)
+	}
+
+	return parse.Float(value)
+}
+
+func (r resolvingSource) Bool(name string) (bool, error) {
+	value, is, err := r.reference(name)
+	if err != nil {
+		return false, err
+	}
+	if !is {
+		return r.Source.Bool(name)
+	}
+
+	return parse.Bool(value)
+}
+
+func (r resolvingSource) Time(name string) (time.Time, error) {
+	value, is, err := r.reference(name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !is {
+		return r.Source.Time(name)
+	}
+
+	return parse.Time(value)
+}
+
+func (r resolvingSource) Duration(name string) (time.Duration, error) {
+	value, is, err := r.reference(name)
+	if err != nil {
+		return time.Duration(0), err
+	}
+	if !is {
+		return r.Source.Duration(name)
+	}
+
+	return parse.Duration(value)
+}
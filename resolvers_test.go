@@ -0,0 +1,90 @@
+package migi
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolvers(t *testing.T) {
+	file, err := ioutil.TempFile("", "migi-resolver")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("file_value\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	relative, err := ioutil.TempFile(".", "migi-resolver")
+	require.NoError(t, err)
+	defer os.Remove(relative.Name())
+	_, err = relative.WriteString("relative_value")
+	require.NoError(t, err)
+	require.NoError(t, relative.Close())
+
+	os.Setenv("MIGI_RESOLVER_KEY", "env_value")
+	defer os.Unsetenv("MIGI_RESOLVER_KEY")
+
+	for reference, expected := range map[string]string{
+		"file://" + file.Name():                  "file_value",
+		"file:" + filepath.Base(relative.Name()): "relative_value",
+		"env://MIGI_RESOLVER_KEY":                "env_value",
+		"env:MIGI_RESOLVER_KEY":                  "env_value",
+		"base64:YmFzZTY0X3ZhbHVl":                "base64_value",
+		"plain_value":                            "plain_value",
+		"unknown://plain_value":                  "unknown://plain_value",
+	} {
+		value, _, err := resolve("my_option", reference)
+		assert.NoError(t, err, reference)
+		assert.Equal(t, expected, value, reference)
+	}
+
+	_, is, err := resolve("my_option", "env://MIGI_RESOLVER_MISSING")
+	assert.True(t, is)
+	assert.EqualError(t, err, "errors.OptionNotResolved{Name='my_option', Scheme='env', Cause='environment variable MIGI_RESOLVER_MISSING is not set'}")
+
+	_, _, err = resolve("my_option", "env:MIGI_RESOLVER_MISSING")
+	assert.EqualError(t, err, "errors.OptionNotResolved{Name='my_option', Scheme='env', Cause='environment variable MIGI_RESOLVER_MISSING is not set'}")
+
+	_, _, err = resolve("my_option", "file:///migi/missing")
+	assert.IsType(t, OptionNotResolved{}, err)
+}
+
+func TestRegisterResolver(t *testing.T) {
+	RegisterResolver("vault", ResolverFunc(func(reference string) (string, error) {
+		if reference != "vault://secret/db#password" {
+			return "", errors.New("mock_not_found")
+		}
+		return "vault_value", nil
+	}))
+	defer RegisterResolver("vault", nil)
+
+	source := NewResolvingSource(
+		&mockSource{
+			options: map[string]interface{}{
+				"string_key": "vault://secret/db#password",
+				"int_key":    333,
+			},
+		},
+	)
+
+	value, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "vault_value", value)
+	intValue, err := source.Int("int_key")
+	assert.NoError(t, err)
+	assert.Equal(t, 333, intValue)
+
+	rawValue, err := source.(Valuer).Value("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "vault_value", rawValue)
+
+	RegisterResolver("vault", nil)
+	value, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "vault://secret/db#password", value)
+}
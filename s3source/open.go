@@ -0,0 +1,27 @@
+package s3source
+
+import (
+	"net/url"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("s3", open)
+}
+
+// open creates the source of s3://bucket/app/prod.json?region=eu-west-1&endpoint=http://minio:9000
+func open(u *url.URL) (migi.Source, error) {
+	query := u.Query()
+	region := query.Get("region")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	var options []Option
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		options = append(options, WithEndpoint(endpoint))
+	}
+
+	return NewSource(region, u.Host, u.Path, options...), nil
+}
@@ -0,0 +1,153 @@
+package s3source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/httpsource"
+	"github.com/rjansen/migi/internal/format"
+	"github.com/rjansen/migi/internal/mapsource"
+	"github.com/rjansen/migi/internal/sigv4"
+)
+
+var emptyPayloadHash = func() string {
+	sum := sha256.Sum256(nil)
+	return hex.EncodeToString(sum[:])
+}()
+
+type (
+	// Option customizes the s3 source
+	Option func(*source)
+
+	source struct {
+		migi.Source
+		region      string
+		bucket      string
+		key         string
+		endpoint    string
+		credentials sigv4.Credentials
+		client      *http.Client
+		etag        string
+		now         func() time.Time
+	}
+)
+
+// WithCredentials replaces the credentials read from the AWS_* environment variables
+func WithCredentials(accessKeyID string, secretAccessKey string, sessionToken string) Option {
+	return func(s *source) {
+		s.credentials = sigv4.Credentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
+		}
+	}
+}
+
+// WithEndpoint replaces the regional s3 endpoint, e.g. with a MinIO address.
+// Objects are always addressed path style
+func WithEndpoint(endpoint string) Option {
+	return func(s *source) {
+		s.endpoint = endpoint
+	}
+}
+
+// WithClient replaces the default http client, which times out after httpsource.DefaultTimeout
+func WithClient(client *http.Client) Option {
+	return func(s *source) {
+		s.client = client
+	}
+}
+
+func (s *source) Load() error {
+	segments := strings.Split(strings.TrimPrefix(s.key, "/"), "/")
+	for index, segment := range segments {
+		segments[index] = url.PathEscape(segment)
+	}
+	endpoint := strings.TrimSuffix(s.endpoint, "/") + "/" + url.PathEscape(s.bucket) + "/" + strings.Join(segments, "/")
+
+	request, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if s.etag != "" {
+		request.Header.Set("If-None-Match", s.etag)
+	}
+	request.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	sigv4.Sign(request, nil, s.credentials, s.region, "s3", s.now())
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		return nil
+	case http.StatusOK:
+	default:
+		return httpsource.NewStatusError(endpoint, response.StatusCode)
+	}
+
+	parser, err := format.NewSource(path.Base(s.key), response.Header.Get("Content-Type"), response.Body)
+	if err != nil {
+		return err
+	}
+	if err := parser.Load(); err != nil {
+		return err
+	}
+	s.Source = parser
+	s.etag = response.Header.Get("ETag")
+
+	return nil
+}
+
+// ETag returns the entity tag of the last loaded object
+func (s *source) ETag() string {
+	return s.etag
+}
+
+// Keys lists the option names of the parsed object, see migi.KeyLister
+func (s *source) Keys() []string {
+	if lister, is := s.Source.(migi.KeyLister); is {
+		return lister.Keys()
+	}
+
+	return nil
+}
+
+func (s *source) Provenance() string {
+	return fmt.Sprintf("s3://%s/%s@%s", s.bucket, strings.TrimPrefix(s.key, "/"), s.etag)
+}
+
+// NewSource creates a source that reads the object at key from bucket, parsed
+// by its content type or extension. Unchanged objects are not parsed again
+func NewSource(region string, bucket string, key string, options ...Option) *source {
+	s := &source{
+		Source:   mapsource.New(nil),
+		region:   region,
+		bucket:   bucket,
+		key:      key,
+		endpoint: fmt.Sprintf("https://s3.%s.amazonaws.com", region),
+		credentials: sigv4.Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		client: &http.Client{Timeout: httpsource.DefaultTimeout},
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
@@ -0,0 +1,123 @@
+package s3source
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/httpsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer is a stand-in for an s3 compatible GetObject endpoint
+type testServer struct {
+	objects     map[string]string
+	contentType string
+	etag        string
+	requests    []*http.Request
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests = append(s.requests, r)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	object, ok := s.objects[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", s.contentType)
+	w.Write([]byte(object))
+}
+
+func TestSource(t *testing.T) {
+	handler := &testServer{
+		objects: map[string]string{
+			"/configs/app/prod.json": `{"string_key": "string_value", "duration_key": "5m"}`,
+			"/configs/app/bundle":    `{"string_key": "bundle_value"}`,
+		},
+		contentType: "binary/octet-stream",
+		etag:        `"v1"`,
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	source := NewSource("us-east-1", "configs", "app/prod.json", WithEndpoint(server.URL), WithCredentials("AKID", "secret", ""))
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.Implements(t, (*migi.Provenancer)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	durationValue, err := source.Duration("duration_key")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute*5, durationValue)
+	assert.Equal(t, `s3://configs/app/prod.json@"v1"`, source.Provenance())
+
+	require.NoError(t, source.Load())
+	assert.Equal(t, `"v1"`, handler.requests[1].Header.Get("If-None-Match"))
+	stringValue, err = source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+
+	handler.contentType = "application/json"
+	bundle := NewSource("us-east-1", "configs", "app/bundle", WithEndpoint(server.URL), WithCredentials("AKID", "secret", ""))
+	require.NoError(t, bundle.Load())
+	stringValue, err = bundle.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "bundle_value", stringValue)
+}
+
+func TestSourceError(t *testing.T) {
+	handler := &testServer{objects: map[string]string{"/configs/app/prod.toml": ``}, etag: `"v1"`}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	_, err := NewSource("us-east-1", "configs", "app/prod.json").String("string_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+
+	assert.IsType(t, httpsource.StatusError{},
+		NewSource("us-east-1", "configs", "app/prod.json", WithEndpoint(server.URL), WithCredentials("invalid", "secret", "")).Load(),
+	)
+	assert.IsType(t, httpsource.StatusError{},
+		NewSource("us-east-1", "configs", "app/missing.json", WithEndpoint(server.URL), WithCredentials("AKID", "secret", "")).Load(),
+	)
+	assert.Equal(t, migi.NewFormatNotSupported("prod.toml"),
+		NewSource("us-east-1", "configs", "app/prod.toml", WithEndpoint(server.URL), WithCredentials("AKID", "secret", "")).Load(),
+	)
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("s3://configs/app/prod.json?region=eu-west-1&endpoint=http://minio:9000")
+	require.NoError(t, err)
+	openedSource := opened.(*source)
+	assert.Equal(t, "eu-west-1", openedSource.region)
+	assert.Equal(t, "configs", openedSource.bucket)
+	assert.Equal(t, "/app/prod.json", openedSource.key)
+	assert.Equal(t, "http://minio:9000", openedSource.endpoint)
+}
+
+func TestSourceKeys(t *testing.T) {
+	server := httptest.NewServer(&testServer{
+		objects: map[string]string{"/configs/app/prod.json": `{"string_key": "string_value", "db.host": "db.local"}`},
+		etag:    `"v1"`,
+	})
+	defer server.Close()
+
+	source := NewSource("us-east-1", "configs", "app/prod.json", WithEndpoint(server.URL), WithCredentials("AKID", "secret", ""))
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"db.host", "string_key"}, source.Keys())
+}
@@ -0,0 +1,59 @@
+package sqlsource
+
+import (
+	"database/sql"
+
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+type source struct {
+	*mapsource.Source
+	db    *sql.DB
+	query string
+	args  []interface{}
+}
+
+func (s *source) Load() error {
+	rows, err := s.db.Query(s.query, s.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make(map[string]interface{})
+	for rows.Next() {
+		var (
+			key   string
+			value sql.NullString
+		)
+		if err := rows.Scan(&key, &value); err != nil {
+			return err
+		}
+		if value.Valid {
+			values[key] = value.String
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	s.Set(values)
+
+	return nil
+}
+
+// Keys lists the option names selected by the query, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+// NewSource creates a source from the rows of query, which must select
+// the option name and its value, e.g. "SELECT key, value FROM settings".
+// Null values are treated as missing options
+func NewSource(db *sql.DB, query string, args ...interface{}) *source {
+	return &source{
+		Source: mapsource.New(nil),
+		db:     db,
+		query:  query,
+		args:   args,
+	}
+}
@@ -0,0 +1,137 @@
+package sqlsource
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// testDriver is a fake database/sql driver answering every query with rows
+	testDriver struct {
+		rows     [][]driver.Value
+		queryErr error
+		queries  []string
+		args     [][]driver.Value
+	}
+
+	testConn struct {
+		driver *testDriver
+	}
+
+	testStmt struct {
+		conn  *testConn
+		query string
+	}
+
+	testRows struct {
+		rows  [][]driver.Value
+		index int
+	}
+)
+
+func (d *testDriver) Connect(context.Context) (driver.Conn, error) { return &testConn{driver: d}, nil }
+func (d *testDriver) Driver() driver.Driver                        { return d }
+func (d *testDriver) Open(string) (driver.Conn, error)             { return &testConn{driver: d}, nil }
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{conn: c, query: query}, nil
+}
+func (c *testConn) Close() error              { return nil }
+func (c *testConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (s *testStmt) Close() error  { return nil }
+func (s *testStmt) NumInput() int { return -1 }
+func (s *testStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.driver.queries = append(s.conn.driver.queries, s.query)
+	s.conn.driver.args = append(s.conn.driver.args, args)
+	if s.conn.driver.queryErr != nil {
+		return nil, s.conn.driver.queryErr
+	}
+	return &testRows{rows: s.conn.driver.rows}, nil
+}
+
+func (r *testRows) Columns() []string { return []string{"key", "value"} }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.index >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.index])
+	r.index++
+	return nil
+}
+
+func TestSource(t *testing.T) {
+	fake := &testDriver{
+		rows: [][]driver.Value{
+			{"string_key", "string_value"},
+			{"int_key", "333"},
+			{"float_key", "333.33"},
+			{"bool_key", "true"},
+			{"time_key", "2019-05-23T00:00:00Z"},
+			{"duration_key", "5m"},
+			{"null_key", nil},
+		},
+	}
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	source := NewSource(db, "SELECT key, value FROM settings WHERE tenant = ?", "tenant_1")
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	assert.Equal(t, []string{"SELECT key, value FROM settings WHERE tenant = ?"}, fake.queries)
+	assert.Equal(t, []driver.Value{"tenant_1"}, fake.args[0])
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	intValue, err := source.Int("int_key")
+	assert.NoError(t, err)
+	assert.Equal(t, 333, intValue)
+	floatValue, err := source.Float("float_key")
+	assert.NoError(t, err)
+	assert.Equal(t, float32(333.33), floatValue)
+	boolValue, err := source.Bool("bool_key")
+	assert.NoError(t, err)
+	assert.True(t, boolValue)
+	timeValue, err := source.Time("time_key")
+	assert.NoError(t, err)
+	assert.Equal(t, testutils.NewTime(t, time.RFC3339, "2019-05-23T00:00:00Z"), timeValue)
+	durationValue, err := source.Duration("duration_key")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute*5, durationValue)
+	_, err = source.String("null_key")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+}
+
+func TestSourceError(t *testing.T) {
+	db := sql.OpenDB(&testDriver{queryErr: errors.New("mock_query_error")})
+	defer db.Close()
+
+	assert.EqualError(t, NewSource(db, "SELECT key, value FROM settings").Load(), "mock_query_error")
+}
+
+func TestSourceKeys(t *testing.T) {
+	db := sql.OpenDB(&testDriver{
+		rows: [][]driver.Value{{"string_key", "string_value"}, {"db.host", "db.local"}, {"null_key", nil}},
+	})
+	defer db.Close()
+
+	source := NewSource(db, "SELECT key, value FROM settings")
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"db.host", "string_key"}, source.Keys())
+}
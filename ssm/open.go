@@ -0,0 +1,21 @@
+package ssm
+
+import (
+	"net/url"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("ssm", open)
+}
+
+// open creates the source of ssm://us-east-1/app/prod
+func open(u *url.URL) (migi.Source, error) {
+	var options []Option
+	if endpoint := u.Query().Get("endpoint"); endpoint != "" {
+		options = append(options, WithEndpoint(endpoint))
+	}
+
+	return NewSource(u.Host, u.Path, options...), nil
+}
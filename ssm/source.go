@@ -0,0 +1,160 @@
+package ssm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi/httpsource"
+	"github.com/rjansen/migi/internal/mapsource"
+	"github.com/rjansen/migi/internal/sigv4"
+)
+
+const target = "AmazonSSM.GetParametersByPath"
+
+type (
+	// Option customizes the ssm source
+	Option func(*source)
+
+	request struct {
+		Path           string
+		Recursive      bool
+		WithDecryption bool
+		NextToken      string `json:",omitempty"`
+	}
+
+	response struct {
+		Parameters []struct {
+			Name  string
+			Value string
+		}
+		NextToken string
+	}
+
+	source struct {
+		*mapsource.Source
+		region      string
+		path        string
+		endpoint    string
+		credentials sigv4.Credentials
+		client      *http.Client
+		now         func() time.Time
+	}
+)
+
+// WithCredentials replaces the credentials read from the AWS_* environment variables
+func WithCredentials(accessKeyID string, secretAccessKey string, sessionToken string) Option {
+	return func(s *source) {
+		s.credentials = sigv4.Credentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+			SessionToken:    sessionToken,
+		}
+	}
+}
+
+// WithEndpoint replaces the regional ssm endpoint
+func WithEndpoint(endpoint string) Option {
+	return func(s *source) {
+		s.endpoint = endpoint
+	}
+}
+
+// WithClient replaces the default http client, which times out after httpsource.DefaultTimeout
+func WithClient(client *http.Client) Option {
+	return func(s *source) {
+		s.client = client
+	}
+}
+
+func (s *source) Load() error {
+	values := make(map[string]interface{})
+	prefix := strings.TrimSuffix(s.path, "/") + "/"
+	next := ""
+	for {
+		page, err := s.page(next)
+		if err != nil {
+			return err
+		}
+		for _, parameter := range page.Parameters {
+			name := strings.TrimPrefix(parameter.Name, prefix)
+			values[strings.Replace(name, "/", ".", -1)] = parameter.Value
+		}
+		if page.NextToken == "" {
+			break
+		}
+		next = page.NextToken
+	}
+	s.Set(values)
+
+	return nil
+}
+
+// Keys lists the option names of the parameters under the path, see migi.KeyLister
+func (s *source) Keys() []string {
+	return s.Names()
+}
+
+func (s *source) page(next string) (*response, error) {
+	body, err := json.Marshal(request{
+		Path:           s.path,
+		Recursive:      true,
+		WithDecryption: true,
+		NextToken:      next,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	httpRequest.Header.Set("X-Amz-Target", target)
+	sigv4.Sign(httpRequest, body, s.credentials, s.region, "ssm", s.now())
+
+	httpResponse, err := s.client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResponse.Body.Close()
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, httpsource.NewStatusError(s.endpoint, httpResponse.StatusCode)
+	}
+
+	var page response
+	if err := json.NewDecoder(httpResponse.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// NewSource creates a source that reads, and decrypts, every parameter under
+// path, naming options by the path relative to it joined by dots
+func NewSource(region string, path string, options ...Option) *source {
+	s := &source{
+		Source:   mapsource.New(nil),
+		region:   region,
+		path:     path,
+		endpoint: fmt.Sprintf("https://ssm.%s.amazonaws.com/", region),
+		credentials: sigv4.Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		},
+		client: &http.Client{Timeout: httpsource.DefaultTimeout},
+		now:    time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
@@ -0,0 +1,108 @@
+package ssm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/httpsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer is a stand-in for the ssm GetParametersByPath api paging one parameter at a time
+type testServer struct {
+	parameters [][2]string
+	requests   []request
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Amz-Target") != target ||
+		!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var body request
+	json.NewDecoder(r.Body).Decode(&body)
+	s.requests = append(s.requests, body)
+
+	index := 0
+	if body.NextToken != "" {
+		index = int(body.NextToken[0] - '0')
+	}
+	page := map[string]interface{}{
+		"Parameters": []map[string]string{{"Name": s.parameters[index][0], "Value": s.parameters[index][1]}},
+	}
+	if index+1 < len(s.parameters) {
+		page["NextToken"] = string(rune('0' + index + 1))
+	}
+	json.NewEncoder(w).Encode(page)
+}
+
+func TestSource(t *testing.T) {
+	handler := &testServer{
+		parameters: [][2]string{
+			{"/app/prod/string_key", "string_value"},
+			{"/app/prod/db/port", "5432"},
+			{"/app/prod/db/password", "decrypted_password"},
+			{"/app/prod/db/timeout", "5s"},
+		},
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	source := NewSource("us-east-1", "/app/prod", WithEndpoint(server.URL), WithCredentials("AKID", "secret", ""))
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	require.Len(t, handler.requests, 4)
+	assert.Equal(t, "/app/prod", handler.requests[0].Path)
+	assert.True(t, handler.requests[0].Recursive)
+	assert.True(t, handler.requests[0].WithDecryption)
+	assert.Equal(t, "3", handler.requests[3].NextToken)
+
+	stringValue, err := source.String("string_key")
+	assert.NoError(t, err)
+	assert.Equal(t, "string_value", stringValue)
+	intValue, err := source.Int("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, intValue)
+	stringValue, err = source.String("db.password")
+	assert.NoError(t, err)
+	assert.Equal(t, "decrypted_password", stringValue)
+	durationValue, err := source.Duration("db.timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second*5, durationValue)
+}
+
+func TestSourceError(t *testing.T) {
+	server := httptest.NewServer(&testServer{})
+	defer server.Close()
+
+	source := NewSource("us-east-1", "/app/prod", WithEndpoint(server.URL), WithCredentials("invalid", "secret", ""))
+	assert.IsType(t, httpsource.StatusError{}, source.Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	opened, err := migi.OpenSource("ssm://eu-west-1/app/prod")
+	require.NoError(t, err)
+	openedSource := opened.(*source)
+	assert.Equal(t, "eu-west-1", openedSource.region)
+	assert.Equal(t, "/app/prod", openedSource.path)
+	assert.Equal(t, "https://ssm.eu-west-1.amazonaws.com/", openedSource.endpoint)
+}
+
+func TestSourceKeys(t *testing.T) {
+	server := httptest.NewServer(&testServer{
+		parameters: [][2]string{{"/app/prod/db/host", "db.local"}, {"/app/prod/string_key", "string_value"}},
+	})
+	defer server.Close()
+
+	source := NewSource("us-east-1", "/app/prod", WithEndpoint(server.URL), WithCredentials("AKID", "secret", ""))
+	require.NoError(t, source.Load())
+	assert.Equal(t, []string{"db.host", "string_key"}, source.Keys())
+}
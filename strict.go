@@ -0,0 +1,113 @@
+package migi
+
+import (
+	"sort"
+	"strings"
+)
+
+// unknown reports the names listed by the layers that are not registered options
+func (o *options) unknown(layers []layer) []error {
+	known := make(map[string]bool)
+	var names []string
+	for _, option := range o.register {
+		known[option.name] = true
+		names = append(names, option.name)
+		for _, deprecated := range option.deprecated {
+			known[deprecated] = true
+		}
+	}
+
+	var errs []error
+	for _, layer := range layers {
+		lister, is := layer.Source.(KeyLister)
+		if !is {
+			continue
+		}
+		for _, key := range lister.Keys() {
+			if !known[key] {
+				errs = append(errs, NewOptionUnknown(key, layer.provenance, suggest(key, names)))
+			}
+		}
+	}
+
+	return errs
+}
+
+// suggest returns the name closest to key, when it is close enough to be a typo.
+// Names are compared ignoring case and separators, so DATABSE_URL suggests database.url
+func suggest(key string, names []string) string {
+	var (
+		suggestion string
+		best       int
+	)
+	normalizedKey := normalize(key)
+	for _, name := range names {
+		distance := editDistance(normalizedKey, normalize(name))
+		if distance > len(normalizedKey)/3 && distance > 1 {
+			continue
+		}
+		if suggestion == "" || distance < best {
+			suggestion, best = name, distance
+		}
+	}
+
+	return suggestion
+}
+
+func normalize(name string) string {
+	return strings.NewReplacer("_", ".", "-", ".").Replace(strings.ToLower(name))
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = smallest(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
+
+func smallest(values ...int) int {
+	smallest := values[0]
+	for _, value := range values[1:] {
+		if value < smallest {
+			smallest = value
+		}
+	}
+
+	return smallest
+}
+
+// sortedKeys returns the sorted, unique names listed by the sources implementing KeyLister
+func sortedKeys(sources ...Source) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, source := range sources {
+		lister, is := source.(KeyLister)
+		if !is {
+			continue
+		}
+		for _, key := range lister.Keys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
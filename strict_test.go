@@ -0,0 +1,145 @@
+package migi
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rjansen/abend"
+	"github.com/rjansen/migi/internal/testutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	// listingMockSource lists the names of its options
+	listingMockSource struct {
+		mockSource
+		provenance string
+	}
+
+	testStrict struct {
+		name      string
+		strict    bool
+		sources   []Source
+		loadError error
+	}
+)
+
+func (l *listingMockSource) Keys() []string {
+	keys := make([]string, 0, len(l.options))
+	for key := range l.options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func (l *listingMockSource) Provenance() string {
+	return l.provenance
+}
+
+func TestOptionsStrict(t *testing.T) {
+	scenarios := []testStrict{
+		{
+			name:   "when every listed key is registered",
+			strict: true,
+			sources: []Source{
+				&listingMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"DATABASE_URL": "postgres://db", "http_timeout": time.Second}},
+					provenance: "file:config.json",
+				},
+			},
+		},
+		{
+			name:   "when listed keys are not registered",
+			strict: true,
+			sources: []Source{
+				&listingMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"DATABSE_URL": "postgres://db", "http.timout": time.Second}},
+					provenance: "file:config.json",
+				},
+				&listingMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"unrelated": "value"}},
+					provenance: "env",
+				},
+				&mockSource{options: map[string]interface{}{"not_listed": "value"}},
+			},
+			loadError: abend.NewList(
+				NewOptionUnknown("DATABSE_URL", "file:config.json", "DATABASE_URL"),
+				NewOptionUnknown("http.timout", "file:config.json", "http.timeout"),
+				NewOptionUnknown("unrelated", "env", ""),
+			),
+		},
+		{
+			name: "when strict mode is off",
+			sources: []Source{
+				&listingMockSource{
+					mockSource: mockSource{options: map[string]interface{}{"DATABSE_URL": "postgres://db"}},
+				},
+			},
+		},
+	}
+
+	for index, scenario := range scenarios {
+		t.Run(
+			testutils.TestName(t, scenario.name, index),
+			func(t *testing.T) {
+				options := NewOptions(scenario.sources...)
+				options.(StrictOptions).Strict(scenario.strict)
+				options.String("DATABASE_URL", "", "database url")
+				options.Duration("http.timeout", 0, "http client timeout")
+				options.(DeprecatedOptions).Deprecate("http.timeout", "http_timeout")
+
+				assert.Equal(t, scenario.loadError, options.Load())
+			},
+		)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	names := []string{"database.url", "http.timeout", "db", "log.level"}
+
+	for key, suggestion := range map[string]string{
+		"DATABSE_URL":   "database.url",
+		"database-url":  "database.url",
+		"HTTP_TIMEOUT":  "http.timeout",
+		"dv":            "db",
+		"log.levle":     "log.level",
+		"cache.enabled": "",
+		"x":             "",
+	} {
+		assert.Equal(t, suggestion, suggest(key, names), key)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("", ""))
+	assert.Equal(t, 3, editDistance("", "abc"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 1, editDistance("databse", "database"))
+}
+
+func TestSourceKeys(t *testing.T) {
+	base := &listingMockSource{mockSource: mockSource{options: map[string]interface{}{
+		"DB_HOST": "db.local", "db.password": "secret", "string_key": "value",
+	}}}
+	other := &listingMockSource{mockSource: mockSource{options: map[string]interface{}{"int_key": 1}}}
+
+	assert.Equal(t, []string{"DB_HOST", "db.password", "int_key", "string_key"}, Merge(base, other, &mockSource{}).(KeyLister).Keys())
+	assert.Equal(t,
+		[]string{"db.host", "string_key"},
+		Decorate(base, WithDenyList("db.*"), WithAliases(map[string]string{"db.host": "DB_HOST"})).(KeyLister).Keys(),
+	)
+	assert.Equal(t, []string{"DB_HOST", "db.password", "string_key"}, Decorate(base, WithCache(time.Minute)).(KeyLister).Keys())
+	assert.Empty(t, Decorate(base, WithRename(func(name string) string { return name })).(KeyLister).Keys())
+	assert.Empty(t, Decorate(&mockSource{}, WithAllowList("*")).(KeyLister).Keys())
+
+	overlay := NewOverlaySource(base, StaticLabels(Labels{"region": "eu-west-1"}),
+		Overlay{Selector: "region=eu-*", Source: other},
+		Overlay{Selector: "region=us-*", Source: &listingMockSource{mockSource: mockSource{options: map[string]interface{}{"us_key": 1}}}},
+	)
+	require.NoError(t, overlay.Load())
+	assert.Equal(t, []string{"DB_HOST", "db.password", "int_key", "string_key"}, overlay.(KeyLister).Keys())
+}
@@ -0,0 +1,16 @@
+package systemdcreds
+
+import (
+	"net/url"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("systemdcreds", open)
+}
+
+// open creates the source of systemdcreds://
+func open(u *url.URL) (migi.Source, error) {
+	return NewSource(), nil
+}
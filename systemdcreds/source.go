@@ -0,0 +1,95 @@
+package systemdcreds
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/internal/mapsource"
+	"github.com/rjansen/migi/internal/parse"
+)
+
+// DirectoryVariable is set by systemd for units using LoadCredential= and friends
+const DirectoryVariable = "CREDENTIALS_DIRECTORY"
+
+// ErrNoCredentialsDirectory is returned when the process was not started with credentials
+var ErrNoCredentialsDirectory = errors.New("systemdcreds: " + DirectoryVariable + " is not set")
+
+type source struct {
+	*mapsource.Source
+}
+
+func (s *source) Load() error {
+	path, ok := os.LookupEnv(DirectoryVariable)
+	if !ok || path == "" {
+		return ErrNoCredentialsDirectory
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return err
+		}
+		// credentials written by echo, or systemd-creds from it, end with a newline
+		values[entry.Name()] = parse.TrimNewline(string(content))
+	}
+	s.Set(values)
+
+	return nil
+}
+
+// The typed getters hide parse errors, which quote the credential value
+
+func (s *source) Int(name string) (int, error) {
+	value, err := s.Source.Int(name)
+	return value, s.sanitize(name, "int", err)
+}
+
+func (s *source) Float(name string) (float32, error) {
+	value, err := s.Source.Float(name)
+	return value, s.sanitize(name, "float", err)
+}
+
+func (s *source) Bool(name string) (bool, error) {
+	value, err := s.Source.Bool(name)
+	return value, s.sanitize(name, "bool", err)
+}
+
+func (s *source) Time(name string) (time.Time, error) {
+	value, err := s.Source.Time(name)
+	return value, s.sanitize(name, "time.Time", err)
+}
+
+func (s *source) Duration(name string) (time.Duration, error) {
+	value, err := s.Source.Duration(name)
+	return value, s.sanitize(name, "time.Duration", err)
+}
+
+func (s *source) sanitize(name string, target string, err error) error {
+	switch err.(type) {
+	case nil, migi.OptionNotFound, migi.OptionInvalidType:
+		return err
+	default:
+		return fmt.Errorf("systemdcreds: credential %s is not a valid %s", name, target)
+	}
+}
+
+// NewSource creates a source where each credential passed by systemd is an
+// option named after the credential. Values are served without one trailing
+// line ending. It lists no keys, see migi.KeyLister, since credentials may be
+// meant for other consumers
+func NewSource() *source {
+	return &source{Source: mapsource.New(nil)}
+}
@@ -0,0 +1,70 @@
+package systemdcreds
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	path, err := ioutil.TempDir("", "migi-systemdcreds")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+	for name, value := range map[string]string{
+		"db.password":  "s3cr3t\n",
+		"db.port":      "5432\n",
+		"db.key":       "line\r\n\n",
+		"db.timeout":   "5s",
+		"invalid_port": "s3cr3t_port",
+	} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(path, name), []byte(value), 0400))
+	}
+	os.Setenv(DirectoryVariable, path)
+	defer os.Unsetenv(DirectoryVariable)
+
+	source := NewSource()
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("db.password")
+	assert.NoError(t, err)
+	assert.Equal(t, "s3cr3t", stringValue)
+	stringValue, err = source.String("db.key")
+	assert.NoError(t, err)
+	assert.Equal(t, "line\r\n", stringValue)
+	intValue, err := source.Int("db.port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, intValue)
+	durationValue, err := source.Duration("db.timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second*5, durationValue)
+
+	_, err = source.Int("missing")
+	assert.IsType(t, migi.OptionNotFound{}, err)
+	_, err = source.Int("invalid_port")
+	assert.EqualError(t, err, "systemdcreds: credential invalid_port is not a valid int")
+	assert.NotContains(t, err.Error(), "s3cr3t")
+}
+
+func TestSourceWithoutDirectory(t *testing.T) {
+	os.Unsetenv(DirectoryVariable)
+
+	assert.Equal(t, ErrNoCredentialsDirectory, NewSource().Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	source, err := migi.OpenSource("systemdcreds://")
+	require.NoError(t, err)
+	assert.Implements(t, (*migi.Source)(nil), source)
+}
+
+func TestSourceKeys(t *testing.T) {
+	_, lists := interface{}(NewSource()).(migi.KeyLister)
+	assert.False(t, lists)
+}
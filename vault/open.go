@@ -0,0 +1,43 @@
+package vault
+
+import (
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rjansen/migi"
+)
+
+func init() {
+	migi.RegisterSource("vault", open)
+}
+
+// open creates the source of vault://host:8200/secret/db?path=secret/api&tls=true,
+// authenticated by the token query parameter, the role_id and secret_id
+// parameters or, by default, the VAULT_TOKEN environment variable
+func open(u *url.URL) (migi.Source, error) {
+	query := u.Query()
+	scheme := "http"
+	if tls, _ := strconv.ParseBool(query.Get("tls")); tls {
+		scheme = "https"
+	}
+
+	var paths []string
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		paths = append(paths, path)
+	}
+	paths = append(paths, query["path"]...)
+
+	var options []Option
+	switch {
+	case query.Get("role_id") != "":
+		options = append(options, WithAppRole(query.Get("role_id"), query.Get("secret_id")))
+	case query.Get("token") != "":
+		options = append(options, WithToken(query.Get("token")))
+	default:
+		options = append(options, WithToken(os.Getenv("VAULT_TOKEN")))
+	}
+
+	return NewSource(scheme+"://"+u.Host, paths, options...), nil
+}
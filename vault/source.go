@@ -0,0 +1,216 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rjansen/migi/httpsource"
+	"github.com/rjansen/migi/internal/mapsource"
+)
+
+// ErrMissingAuth is returned when neither a token nor an approle was configured
+var ErrMissingAuth = errors.New("vault: token or approle authentication is required")
+
+type (
+	// Option customizes the vault source
+	Option func(*source)
+
+	// Secret describes the last read version of a kv v2 secret
+	Secret struct {
+		Path          string
+		Version       int
+		LeaseID       string
+		LeaseDuration time.Duration
+	}
+
+	secretResponse struct {
+		LeaseID       string `json:"lease_id"`
+		LeaseDuration int    `json:"lease_duration"`
+		Data          struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+
+	loginResponse struct {
+		Auth struct {
+			ClientToken   string `json:"client_token"`
+			LeaseDuration int    `json:"lease_duration"`
+		} `json:"auth"`
+	}
+
+	source struct {
+		*mapsource.Source
+		address      string
+		paths        []string
+		client       *http.Client
+		token        string
+		tokenExpires time.Time
+		roleID       string
+		secretID     string
+		secrets      map[string]Secret
+		now          func() time.Time
+	}
+)
+
+// WithToken authenticates every request with token
+func WithToken(token string) Option {
+	return func(s *source) {
+		s.token = token
+	}
+}
+
+// WithAppRole authenticates with an approle login, repeated when the token lease expires
+func WithAppRole(roleID string, secretID string) Option {
+	return func(s *source) {
+		s.roleID = roleID
+		s.secretID = secretID
+	}
+}
+
+// WithClient replaces the default http client, which times out after httpsource.DefaultTimeout
+func WithClient(client *http.Client) Option {
+	return func(s *source) {
+		s.client = client
+	}
+}
+
+func (s *source) Load() error {
+	if err := s.authenticate(); err != nil {
+		return err
+	}
+
+	values := make(map[string]interface{})
+	secrets := make(map[string]Secret, len(s.paths))
+	for _, path := range s.paths {
+		var secret secretResponse
+		if err := s.do(http.MethodGet, s.dataPath(path), nil, &secret); err != nil {
+			return err
+		}
+		flatten("", secret.Data.Data, values)
+		secrets[path] = Secret{
+			Path:          path,
+			Version:       secret.Data.Metadata.Version,
+			LeaseID:       secret.LeaseID,
+			LeaseDuration: time.Duration(secret.LeaseDuration) * time.Second,
+		}
+	}
+	s.Set(values)
+	s.secrets = secrets
+
+	return nil
+}
+
+// Secret returns the version and lease information of the last load of path
+func (s *source) Secret(path string) (Secret, bool) {
+	secret, ok := s.secrets[path]
+	return secret, ok
+}
+
+func (s *source) authenticate() error {
+	if s.roleID == "" {
+		if s.token == "" {
+			return ErrMissingAuth
+		}
+		return nil
+	}
+	if s.token != "" && (s.tokenExpires.IsZero() || s.now().Before(s.tokenExpires)) {
+		return nil
+	}
+
+	var login loginResponse
+	body := map[string]string{"role_id": s.roleID, "secret_id": s.secretID}
+	s.token = ""
+	if err := s.do(http.MethodPost, "auth/approle/login", body, &login); err != nil {
+		return err
+	}
+	s.token = login.Auth.ClientToken
+	s.tokenExpires = time.Time{}
+	if login.Auth.LeaseDuration > 0 {
+		s.tokenExpires = s.now().Add(time.Duration(login.Auth.LeaseDuration) * time.Second)
+	}
+
+	return nil
+}
+
+// dataPath maps a mount/path secret to its kv v2 data endpoint
+func (s *source) dataPath(path string) string {
+	path = strings.Trim(path, "/")
+	index := strings.Index(path, "/")
+	if index < 0 {
+		return path + "/data"
+	}
+	return path[:index] + "/data" + path[index:]
+}
+
+func (s *source) do(method string, path string, body interface{}, target interface{}) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+	endpoint := strings.TrimSuffix(s.address, "/") + "/v1/" + path
+	request, err := http.NewRequest(method, endpoint, &payload)
+	if err != nil {
+		return err
+	}
+	if s.token != "" {
+		request.Header.Set("X-Vault-Token", s.token)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return httpsource.NewStatusError(endpoint, response.StatusCode)
+	}
+
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+// flatten joins nested secret data keys with dots
+func flatten(prefix string, data map[string]interface{}, values map[string]interface{}) {
+	for key, value := range data {
+		name := prefix + key
+		switch rawValue := value.(type) {
+		case map[string]interface{}:
+			flatten(name+".", rawValue, values)
+		case string, bool, float64:
+			values[name] = rawValue
+		case nil:
+		default:
+			values[name] = fmt.Sprint(rawValue)
+		}
+	}
+}
+
+// NewSource creates a source that reads the kv v2 secrets at paths, written
+// as mount/path, from the vault server at address. Later paths override keys
+// of earlier ones. It lists no keys, see migi.KeyLister, since secrets are
+// often shared with other services and hold fields unknown to the options
+func NewSource(address string, paths []string, options ...Option) *source {
+	s := &source{
+		Source:  mapsource.New(nil),
+		address: address,
+		paths:   paths,
+		client:  &http.Client{Timeout: httpsource.DefaultTimeout},
+		secrets: make(map[string]Secret),
+		now:     time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+
+	return s
+}
@@ -0,0 +1,161 @@
+package vault
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rjansen/migi"
+	"github.com/rjansen/migi/httpsource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServer is a stand-in for the vault approle login and kv v2 read endpoints
+type testServer struct {
+	roleID   string
+	secretID string
+	token    string
+	logins   int
+	secrets  map[string]map[string]interface{}
+	versions map[string]int
+}
+
+func (v *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost && r.URL.Path == "/v1/auth/approle/login" {
+		var login map[string]string
+		json.NewDecoder(r.Body).Decode(&login)
+		if login["role_id"] != v.roleID || login["secret_id"] != v.secretID {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		v.logins++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": v.token, "lease_duration": 60},
+		})
+		return
+	}
+	if r.Header.Get("X-Vault-Token") != v.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v1/")
+	data, ok := v.secrets[path]
+	if r.Method != http.MethodGet || !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"lease_id":       "",
+		"lease_duration": 0,
+		"data": map[string]interface{}{
+			"data":     data,
+			"metadata": map[string]interface{}{"version": v.versions[path]},
+		},
+	})
+}
+
+func newTestServer() *testServer {
+	return &testServer{
+		roleID:   "my_role",
+		secretID: "my_secret",
+		token:    "my_token",
+		secrets: map[string]map[string]interface{}{
+			"secret/data/db": {
+				"password": "db_password",
+				"port":     5432,
+				"pool":     map[string]interface{}{"timeout": "5s"},
+			},
+			"secret/data/app/api": {
+				"password": "api_password",
+				"debug":    true,
+			},
+		},
+		versions: map[string]int{"secret/data/db": 3, "secret/data/app/api": 1},
+	}
+}
+
+func TestSource(t *testing.T) {
+	server := httptest.NewServer(newTestServer())
+	defer server.Close()
+
+	source := NewSource(server.URL, []string{"secret/db", "secret/app/api"}, WithToken("my_token"))
+	require.Implements(t, (*migi.Source)(nil), source)
+	require.NoError(t, source.Load())
+
+	stringValue, err := source.String("password")
+	assert.NoError(t, err)
+	assert.Equal(t, "api_password", stringValue)
+	intValue, err := source.Int("port")
+	assert.NoError(t, err)
+	assert.Equal(t, 5432, intValue)
+	durationValue, err := source.Duration("pool.timeout")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second*5, durationValue)
+	boolValue, err := source.Bool("debug")
+	assert.NoError(t, err)
+	assert.True(t, boolValue)
+
+	secret, ok := source.Secret("secret/db")
+	assert.True(t, ok)
+	assert.Equal(t, 3, secret.Version)
+	_, ok = source.Secret("secret/missing")
+	assert.False(t, ok)
+}
+
+func TestSourceAppRole(t *testing.T) {
+	vault := newTestServer()
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	now := time.Now()
+	source := NewSource(server.URL, []string{"secret/db"}, WithAppRole("my_role", "my_secret"))
+	source.now = func() time.Time { return now }
+
+	require.NoError(t, source.Load())
+	require.NoError(t, source.Load())
+	assert.Equal(t, 1, vault.logins)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, source.Load())
+	assert.Equal(t, 2, vault.logins)
+
+	stringValue, err := source.String("password")
+	assert.NoError(t, err)
+	assert.Equal(t, "db_password", stringValue)
+}
+
+func TestSourceError(t *testing.T) {
+	server := httptest.NewServer(newTestServer())
+	defer server.Close()
+
+	assert.Equal(t, ErrMissingAuth, NewSource(server.URL, []string{"secret/db"}).Load())
+	assert.IsType(t, httpsource.StatusError{}, NewSource(server.URL, []string{"secret/db"}, WithToken("invalid")).Load())
+	assert.IsType(t, httpsource.StatusError{}, NewSource(server.URL, []string{"secret/missing"}, WithToken("my_token")).Load())
+	assert.IsType(t, httpsource.StatusError{}, NewSource(server.URL, []string{"secret/db"}, WithAppRole("my_role", "invalid")).Load())
+}
+
+func TestOpenSource(t *testing.T) {
+	os.Setenv("VAULT_TOKEN", "env_token")
+	defer os.Unsetenv("VAULT_TOKEN")
+
+	opened, err := migi.OpenSource("vault://vault.local:8200/secret/db?path=secret/app/api&tls=true")
+	require.NoError(t, err)
+	openedSource := opened.(*source)
+	assert.Equal(t, "https://vault.local:8200", openedSource.address)
+	assert.Equal(t, []string{"secret/db", "secret/app/api"}, openedSource.paths)
+	assert.Equal(t, "env_token", openedSource.token)
+
+	opened, err = migi.OpenSource("vault://vault.local:8200/secret/db?role_id=my_role&secret_id=my_secret")
+	require.NoError(t, err)
+	assert.Equal(t, "my_role", opened.(*source).roleID)
+}
+
+func TestSourceKeys(t *testing.T) {
+	_, lists := interface{}(NewSource("http://vault.local", []string{"secret/db"})).(migi.KeyLister)
+	assert.False(t, lists)
+}